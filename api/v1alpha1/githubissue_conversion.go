@@ -0,0 +1,159 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"fmt"
+
+	"my.domain/githubissue/api/v1beta1"
+	"sigs.k8s.io/controller-runtime/pkg/conversion"
+)
+
+// ConvertTo converts the GitHubIssue to the v1beta1 hub version.
+func (src *GitHubIssue) ConvertTo(dstRaw conversion.Hub) error {
+	dst, ok := dstRaw.(*v1beta1.GitHubIssue)
+	if !ok {
+		return fmt.Errorf("expected a v1beta1 GitHubIssue but got a %T", dstRaw)
+	}
+	dst.ObjectMeta = src.ObjectMeta
+
+	dst.Spec = v1beta1.GitHubIssueSpec{
+		Repo:            src.Spec.Repo,
+		Title:           src.Spec.Title,
+		Description:     src.Spec.Description,
+		Values:          src.Spec.Values,
+		Labels:          src.Spec.Labels,
+		Assignees:       src.Spec.Assignees,
+		State:           v1beta1.IssueState(src.Spec.State),
+		StateReason:     v1beta1.StateReason(src.Spec.StateReason),
+		StatePolicy:     v1beta1.StatePolicy(src.Spec.StatePolicy),
+		DeletionPolicy:  v1beta1.DeletionPolicy(src.Spec.DeletionPolicy),
+		DeletionComment: src.Spec.DeletionComment,
+		TransferTo:      src.Spec.TransferTo,
+		SyncPolicy:      v1beta1.SyncPolicy(src.Spec.SyncPolicy),
+		Adopt:           src.Spec.Adopt,
+	}
+	if src.Spec.Template != nil {
+		dst.Spec.Template = &v1beta1.IssueTemplate{
+			Name:     src.Spec.Template.Name,
+			Key:      src.Spec.Template.Key,
+			TitleKey: src.Spec.Template.TitleKey,
+		}
+	}
+	if src.Spec.Milestone != 0 || src.Spec.MilestoneRef != "" {
+		dst.Spec.Milestone = &v1beta1.MilestoneReference{Number: src.Spec.Milestone, Name: src.Spec.MilestoneRef}
+	}
+	if src.Spec.CredentialsRef != nil {
+		dst.Spec.CredentialsRef = &v1beta1.CredentialsRef{
+			Name: src.Spec.CredentialsRef.Name,
+			Key:  src.Spec.CredentialsRef.Key,
+			Type: v1beta1.CredentialsType(src.Spec.CredentialsRef.Type),
+		}
+	}
+
+	dst.Status = v1beta1.GitHubIssueStatus{
+		Conditions:         src.Status.Conditions,
+		Number:             src.Status.Number,
+		NodeID:             src.Status.NodeID,
+		HTMLURL:            src.Status.HTMLURL,
+		Title:              src.Status.Title,
+		Labels:             src.Status.Labels,
+		Assignees:          src.Status.Assignees,
+		Milestone:          src.Status.Milestone,
+		State:              v1beta1.IssueState(src.Status.State),
+		StateReason:        src.Status.StateReason,
+		DriftedFields:      src.Status.DriftedFields,
+		PullRequestCount:   src.Status.PullRequestCount,
+		LastSyncedTime:     src.Status.LastSyncedTime,
+		ObservedGeneration: src.Status.ObservedGeneration,
+	}
+	if src.Status.PullRequests != nil {
+		dst.Status.PullRequests = make([]v1beta1.LinkedPullRequest, 0, len(src.Status.PullRequests))
+		for _, pullRequest := range src.Status.PullRequests {
+			dst.Status.PullRequests = append(dst.Status.PullRequests, v1beta1.LinkedPullRequest(pullRequest))
+		}
+	}
+	return nil
+}
+
+// ConvertFrom converts the GitHubIssue from the v1beta1 hub version.
+func (dst *GitHubIssue) ConvertFrom(srcRaw conversion.Hub) error {
+	src, ok := srcRaw.(*v1beta1.GitHubIssue)
+	if !ok {
+		return fmt.Errorf("expected a v1beta1 GitHubIssue but got a %T", srcRaw)
+	}
+	dst.ObjectMeta = src.ObjectMeta
+
+	dst.Spec = GitHubIssueSpec{
+		Repo:            src.Spec.Repo,
+		Title:           src.Spec.Title,
+		Description:     src.Spec.Description,
+		Values:          src.Spec.Values,
+		Labels:          src.Spec.Labels,
+		Assignees:       src.Spec.Assignees,
+		State:           string(src.Spec.State),
+		StateReason:     string(src.Spec.StateReason),
+		StatePolicy:     StatePolicy(src.Spec.StatePolicy),
+		DeletionPolicy:  DeletionPolicy(src.Spec.DeletionPolicy),
+		DeletionComment: src.Spec.DeletionComment,
+		TransferTo:      src.Spec.TransferTo,
+		SyncPolicy:      SyncPolicy(src.Spec.SyncPolicy),
+		Adopt:           src.Spec.Adopt,
+	}
+	if src.Spec.Template != nil {
+		dst.Spec.Template = &IssueTemplate{
+			Name:     src.Spec.Template.Name,
+			Key:      src.Spec.Template.Key,
+			TitleKey: src.Spec.Template.TitleKey,
+		}
+	}
+	if src.Spec.Milestone != nil {
+		dst.Spec.Milestone = src.Spec.Milestone.Number
+		dst.Spec.MilestoneRef = src.Spec.Milestone.Name
+	}
+	if src.Spec.CredentialsRef != nil {
+		dst.Spec.CredentialsRef = &CredentialsRef{
+			Name: src.Spec.CredentialsRef.Name,
+			Key:  src.Spec.CredentialsRef.Key,
+			Type: CredentialsType(src.Spec.CredentialsRef.Type),
+		}
+	}
+
+	dst.Status = GitHubIssueStatus{
+		Conditions:         src.Status.Conditions,
+		Number:             src.Status.Number,
+		NodeID:             src.Status.NodeID,
+		HTMLURL:            src.Status.HTMLURL,
+		Title:              src.Status.Title,
+		Labels:             src.Status.Labels,
+		Assignees:          src.Status.Assignees,
+		Milestone:          src.Status.Milestone,
+		State:              string(src.Status.State),
+		StateReason:        src.Status.StateReason,
+		DriftedFields:      src.Status.DriftedFields,
+		PullRequestCount:   src.Status.PullRequestCount,
+		LastSyncedTime:     src.Status.LastSyncedTime,
+		ObservedGeneration: src.Status.ObservedGeneration,
+	}
+	if src.Status.PullRequests != nil {
+		dst.Status.PullRequests = make([]LinkedPullRequest, 0, len(src.Status.PullRequests))
+		for _, pullRequest := range src.Status.PullRequests {
+			dst.Status.PullRequests = append(dst.Status.PullRequests, LinkedPullRequest(pullRequest))
+		}
+	}
+	return nil
+}
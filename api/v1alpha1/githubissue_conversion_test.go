@@ -0,0 +1,131 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	maromdanaiov1alpha1 "my.domain/githubissue/api/v1alpha1"
+	"my.domain/githubissue/api/v1beta1"
+)
+
+var _ = Describe("GitHubIssue conversion", func() {
+	objectMeta := metav1.ObjectMeta{
+		Name:       "converted",
+		Namespace:  "default",
+		Labels:     map[string]string{"team": "platform"},
+		Finalizers: []string{"marom.dana.io/finalizer"},
+	}
+	conditions := []metav1.Condition{{
+		Type:               "IssueSynced",
+		Status:             metav1.ConditionTrue,
+		Reason:             "IssueSynced",
+		Message:            "Issue is synced",
+		LastTransitionTime: metav1.Now(),
+	}}
+	lastSyncedTime := metav1.Now()
+	pullRequests := []maromdanaiov1alpha1.LinkedPullRequest{{Repo: "MaromC/GitHubIssue-Operator", Number: 12, URL: "https://github.com/MaromC/GitHubIssue-Operator/pull/12", State: "closed", Merged: true}}
+
+	It("should round trip a v1alpha1 GitHubIssue through the v1beta1 hub", func() {
+		original := &maromdanaiov1alpha1.GitHubIssue{
+			ObjectMeta: objectMeta,
+			Spec: maromdanaiov1alpha1.GitHubIssueSpec{
+				Repo:            "MaromC/GitHubIssue-Operator",
+				Title:           "Converted Issue",
+				Description:     "This is a converted issue",
+				Template:        &maromdanaiov1alpha1.IssueTemplate{Name: "issue-template", Key: "body", TitleKey: "title"},
+				Values:          map[string]string{"team": "platform"},
+				Labels:          []string{"bug"},
+				Assignees:       []string{"octocat"},
+				Milestone:       3,
+				MilestoneRef:    "release",
+				State:           "closed",
+				StateReason:     "not_planned",
+				StatePolicy:     maromdanaiov1alpha1.StatePolicyRespectRemote,
+				DeletionPolicy:  maromdanaiov1alpha1.DeletionPolicyTransfer,
+				DeletionComment: "Moved to the archive",
+				TransferTo:      "MaromC/Archive",
+				SyncPolicy:      maromdanaiov1alpha1.SyncPolicyObserveOnly,
+				Adopt:           true,
+				CredentialsRef:  &maromdanaiov1alpha1.CredentialsRef{Name: "github-app", Key: "token", Type: maromdanaiov1alpha1.CredentialsTypeGitHubApp},
+			},
+			Status: maromdanaiov1alpha1.GitHubIssueStatus{
+				Conditions:         conditions,
+				Number:             7,
+				NodeID:             "I_kwDOA",
+				HTMLURL:            "https://github.com/MaromC/GitHubIssue-Operator/issues/7",
+				Title:              "Converted Issue",
+				Labels:             []string{"bug"},
+				Assignees:          []string{"octocat"},
+				Milestone:          3,
+				State:              "closed",
+				StateReason:        "not_planned",
+				DriftedFields:      []string{"title"},
+				PullRequests:       pullRequests,
+				PullRequestCount:   1,
+				LastSyncedTime:     &lastSyncedTime,
+				ObservedGeneration: 2,
+			},
+		}
+
+		hub := &v1beta1.GitHubIssue{}
+		Expect(original.ConvertTo(hub)).To(Succeed())
+		Expect(hub.Spec.Milestone).To(Equal(&v1beta1.MilestoneReference{Number: 3, Name: "release"}))
+		Expect(hub.Spec.State).To(Equal(v1beta1.IssueStateClosed))
+
+		converted := &maromdanaiov1alpha1.GitHubIssue{}
+		Expect(converted.ConvertFrom(hub)).To(Succeed())
+		Expect(converted).To(Equal(original))
+	})
+
+	It("should round trip a v1beta1 GitHubIssue through v1alpha1", func() {
+		original := &v1beta1.GitHubIssue{
+			ObjectMeta: objectMeta,
+			Spec: v1beta1.GitHubIssueSpec{
+				Repo:           "MaromC/GitHubIssue-Operator",
+				Title:          "Converted Issue",
+				Milestone:      &v1beta1.MilestoneReference{Name: "release"},
+				State:          v1beta1.IssueStateOpen,
+				StatePolicy:    v1beta1.StatePolicyEnforce,
+				DeletionPolicy: v1beta1.DeletionPolicyClose,
+				SyncPolicy:     v1beta1.SyncPolicyEnforce,
+			},
+			Status: v1beta1.GitHubIssueStatus{
+				Conditions:   conditions,
+				Number:       7,
+				State:        v1beta1.IssueStateOpen,
+				PullRequests: []v1beta1.LinkedPullRequest{},
+			},
+		}
+
+		spoke := &maromdanaiov1alpha1.GitHubIssue{}
+		Expect(spoke.ConvertFrom(original)).To(Succeed())
+		Expect(spoke.Spec.Milestone).To(BeZero())
+		Expect(spoke.Spec.MilestoneRef).To(Equal("release"))
+
+		converted := &v1beta1.GitHubIssue{}
+		Expect(spoke.ConvertTo(converted)).To(Succeed())
+		Expect(converted).To(Equal(original))
+	})
+
+	It("should leave the milestone unset when the v1alpha1 GitHubIssue has none", func() {
+		hub := &v1beta1.GitHubIssue{}
+		Expect((&maromdanaiov1alpha1.GitHubIssue{Spec: maromdanaiov1alpha1.GitHubIssueSpec{Repo: "MaromC/GitHubIssue-Operator"}}).ConvertTo(hub)).To(Succeed())
+		Expect(hub.Spec.Milestone).To(BeNil())
+	})
+})
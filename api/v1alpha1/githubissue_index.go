@@ -0,0 +1,55 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"fmt"
+	"strings"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// IssueKeyField is the field GitHubIssues are indexed by in the manager cache, holding the keys of the issue they manage.
+// The index is registered by the GitHubIssue controller.
+const IssueKeyField = "issueKey"
+
+// IssueKeys returns the keys of the issue managed by a GitHubIssue: its repo and title, and its repo and number once it is known.
+// Titles rendered from a template are only known when reconciling, so they are not indexed.
+func IssueKeys(obj client.Object) []string {
+	githubIssue, ok := obj.(*GitHubIssue)
+	if !ok {
+		return nil
+	}
+	var keys []string
+	if githubIssue.Spec.Template == nil || githubIssue.Spec.Template.TitleKey == "" {
+		keys = append(keys, TitleKey(githubIssue.Spec.Repo, githubIssue.Spec.Title))
+	}
+	if githubIssue.Status.Number != 0 {
+		keys = append(keys, NumberKey(githubIssue.Spec.Repo, githubIssue.Status.Number))
+	}
+	return keys
+}
+
+// TitleKey returns the key of the issue with the title in the repo, repos are compared ignoring case like GitHub does.
+func TitleKey(repo string, title string) string {
+	return fmt.Sprintf("title:%s:%s", strings.ToLower(NormalizeRepo(repo)), title)
+}
+
+// NumberKey returns the key of the issue with the number in the repo.
+func NumberKey(repo string, number int) string {
+	return fmt.Sprintf("number:%s#%d", strings.ToLower(NormalizeRepo(repo)), number)
+}
@@ -0,0 +1,38 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+const (
+	// OwnershipMarkerFormat is the hidden HTML comment embedded at the end of the body of the issues created by the operator,
+	// formatted with the cluster id, namespace, name and uid of the GitHubIssue
+	OwnershipMarkerFormat = "<!-- github-operator: cluster=%s namespace=%s name=%s uid=%s -->"
+	// OwnershipMarkerSeparator is the blank line separating the ownership marker from the description
+	OwnershipMarkerSeparator = "\n\n"
+	// MaxClusterIDLength is the number of characters the cluster id written in the ownership marker is capped at
+	MaxClusterIDLength = 63
+	// MaxOwnershipMarkerLength is the number of characters the ownership marker and its separator take at most
+	MaxOwnershipMarkerLength = len(OwnershipMarkerSeparator) + len(OwnershipMarkerFormat) - 4*len("%s") +
+		MaxClusterIDLength + maxNamespaceLength + maxNameLength + uidLength
+	// MaxIssueBodyLength is the number of characters GitHub caps issue bodies at
+	MaxIssueBodyLength = 65536
+	// MaxDescriptionLength is the number of characters of the description which leave room for the ownership marker in the issue body
+	MaxDescriptionLength = MaxIssueBodyLength - MaxOwnershipMarkerLength
+
+	maxNamespaceLength = 63
+	maxNameLength      = 253
+	uidLength          = 36
+)
@@ -0,0 +1,196 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+var (
+	// log is for logging in this package.
+	githubissuelog = logf.Log.WithName("githubissue-resource")
+
+	repoPattern = regexp.MustCompile(`^[a-zA-Z0-9_.-]+/[a-zA-Z0-9_.-]+$`)
+)
+
+// SetupWebhookWithManager registers the defaulting and validating webhooks of GitHubIssue.
+// The validating webhook keeps titles unique per repo with the IssueKeyField index of the manager cache.
+func (r *GitHubIssue) SetupWebhookWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewWebhookManagedBy(mgr).
+		For(r).
+		WithDefaulter(&GitHubIssueDefaulter{}).
+		WithValidator(&GitHubIssueValidator{Client: mgr.GetClient()}).
+		Complete()
+}
+
+//+kubebuilder:webhook:path=/mutate-marom-dana-io-dana-io-v1alpha1-githubissue,mutating=true,failurePolicy=fail,sideEffects=None,groups=marom.dana.io.dana.io,resources=githubissues,verbs=create;update,versions=v1alpha1,name=mgithubissue.kb.io,admissionReviewVersions=v1
+
+// GitHubIssueDefaulter defaults the GitHubIssues
+// +kubebuilder:object:generate=false
+type GitHubIssueDefaulter struct{}
+
+var _ webhook.CustomDefaulter = &GitHubIssueDefaulter{}
+
+// Default turns a repo url into its owner/repo and trims the title, so the CRD validation sees the canonical values.
+func (d *GitHubIssueDefaulter) Default(ctx context.Context, obj runtime.Object) error {
+	githubIssue, ok := obj.(*GitHubIssue)
+	if !ok {
+		return fmt.Errorf("expected a GitHubIssue but got a %T", obj)
+	}
+	githubissuelog.Info("default", "namespace", githubIssue.Namespace, "name", githubIssue.Name)
+
+	githubIssue.Spec.Repo = NormalizeRepo(githubIssue.Spec.Repo)
+	githubIssue.Spec.Title = strings.TrimSpace(githubIssue.Spec.Title)
+	return nil
+}
+
+// NormalizeRepo returns the owner/repo of a repo given as a url, like https://github.com/owner/repo.git,
+// and the repo unchanged otherwise.
+func NormalizeRepo(repo string) string {
+	repo = strings.TrimSpace(repo)
+	if _, path, found := strings.Cut(repo, "://"); found {
+		_, repo, _ = strings.Cut(path, "/")
+	}
+	return strings.TrimSuffix(strings.Trim(repo, "/"), ".git")
+}
+
+//+kubebuilder:webhook:path=/validate-marom-dana-io-dana-io-v1alpha1-githubissue,mutating=false,failurePolicy=fail,sideEffects=None,groups=marom.dana.io.dana.io,resources=githubissues,verbs=create;update,versions=v1alpha1,name=vgithubissue.kb.io,admissionReviewVersions=v1
+
+// GitHubIssueValidator validates the GitHubIssues
+// +kubebuilder:object:generate=false
+type GitHubIssueValidator struct {
+	// Client lists the GitHubIssues of the cluster the titles have to be unique among, by their IssueKeyField index
+	Client client.Reader
+}
+
+var _ webhook.CustomValidator = &GitHubIssueValidator{}
+
+// ValidateCreate validates the spec of a new GitHubIssue and that no other GitHubIssue manages an issue with its title in its repo.
+func (v *GitHubIssueValidator) ValidateCreate(ctx context.Context, obj runtime.Object) (admission.Warnings, error) {
+	githubIssue, ok := obj.(*GitHubIssue)
+	if !ok {
+		return nil, fmt.Errorf("expected a GitHubIssue but got a %T", obj)
+	}
+	githubissuelog.Info("validate create", "namespace", githubIssue.Namespace, "name", githubIssue.Name)
+
+	errs := validateSpec(&githubIssue.Spec)
+	if len(errs) == 0 {
+		duplicates, err := v.duplicateTitle(ctx, githubIssue)
+		if err != nil {
+			return nil, err
+		}
+		errs = append(errs, duplicates...)
+	}
+	return nil, invalid(githubIssue, errs)
+}
+
+// ValidateUpdate validates the spec of an updated GitHubIssue and that its repo did not change.
+// The title uniqueness is only checked when the title changes, so GitHubIssues created before the webhook can still be updated,
+// and GitHubIssues being deleted are not validated, so their finalizer can always be removed.
+func (v *GitHubIssueValidator) ValidateUpdate(ctx context.Context, oldObj, newObj runtime.Object) (admission.Warnings, error) {
+	oldIssue, ok := oldObj.(*GitHubIssue)
+	if !ok {
+		return nil, fmt.Errorf("expected a GitHubIssue but got a %T", oldObj)
+	}
+	githubIssue, ok := newObj.(*GitHubIssue)
+	if !ok {
+		return nil, fmt.Errorf("expected a GitHubIssue but got a %T", newObj)
+	}
+	githubissuelog.Info("validate update", "namespace", githubIssue.Namespace, "name", githubIssue.Name)
+	if !githubIssue.DeletionTimestamp.IsZero() {
+		return nil, nil
+	}
+
+	errs := validateSpec(&githubIssue.Spec)
+	if !strings.EqualFold(NormalizeRepo(oldIssue.Spec.Repo), githubIssue.Spec.Repo) {
+		errs = append(errs, field.Forbidden(field.NewPath("spec", "repo"), "repo is immutable, create a new GitHubIssue to manage an issue in another repo"))
+	}
+	if len(errs) == 0 && oldIssue.Spec.Title != githubIssue.Spec.Title {
+		duplicates, err := v.duplicateTitle(ctx, githubIssue)
+		if err != nil {
+			return nil, err
+		}
+		errs = append(errs, duplicates...)
+	}
+	return nil, invalid(githubIssue, errs)
+}
+
+// ValidateDelete allows every deletion, the deletion policy decides what happens to the issue.
+func (v *GitHubIssueValidator) ValidateDelete(ctx context.Context, obj runtime.Object) (admission.Warnings, error) {
+	return nil, nil
+}
+
+// validateSpec validates the repo, title and description of the spec.
+func validateSpec(spec *GitHubIssueSpec) field.ErrorList {
+	var errs field.ErrorList
+	specPath := field.NewPath("spec")
+
+	if !repoPattern.MatchString(spec.Repo) {
+		errs = append(errs, field.Invalid(specPath.Child("repo"), spec.Repo, "repo must be an owner/repo"))
+	}
+	titleRendered := spec.Template != nil && spec.Template.TitleKey != ""
+	if strings.TrimSpace(spec.Title) == "" && !titleRendered {
+		errs = append(errs, field.Required(specPath.Child("title"), "title is required when it is not rendered from a template"))
+	}
+	if utf8.RuneCountInString(spec.Description) > MaxDescriptionLength {
+		errs = append(errs, field.TooLong(specPath.Child("description"), "", MaxDescriptionLength))
+	}
+	return errs
+}
+
+// duplicateTitle returns an error when another GitHubIssue of the cluster manages an issue with the same title in the same repo.
+// Titles rendered from a template are only known when reconciling, so they are not checked.
+func (v *GitHubIssueValidator) duplicateTitle(ctx context.Context, githubIssue *GitHubIssue) (field.ErrorList, error) {
+	if githubIssue.Spec.Template != nil && githubIssue.Spec.Template.TitleKey != "" {
+		return nil, nil
+	}
+
+	githubIssues := &GitHubIssueList{}
+	key := TitleKey(githubIssue.Spec.Repo, githubIssue.Spec.Title)
+	if err := v.Client.List(ctx, githubIssues, client.MatchingFields{IssueKeyField: key}); err != nil {
+		return nil, apierrors.NewInternalError(fmt.Errorf("unable to list GitHubIssues: %w", err))
+	}
+	for _, other := range githubIssues.Items {
+		if other.Namespace != githubIssue.Namespace || other.Name != githubIssue.Name {
+			return field.ErrorList{field.Invalid(field.NewPath("spec", "title"), githubIssue.Spec.Title,
+				fmt.Sprintf("the issue is already managed in %s by GitHubIssue %s/%s", githubIssue.Spec.Repo, other.Namespace, other.Name))}, nil
+		}
+	}
+	return nil, nil
+}
+
+// invalid returns the Invalid error of the GitHubIssue with the field errors, or nil when there are none.
+func invalid(githubIssue *GitHubIssue, errs field.ErrorList) error {
+	if len(errs) == 0 {
+		return nil
+	}
+	return apierrors.NewInvalid(schema.GroupKind{Group: GroupVersion.Group, Kind: "GitHubIssue"}, githubIssue.Name, errs)
+}
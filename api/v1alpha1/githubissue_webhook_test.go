@@ -0,0 +1,113 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1_test
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	maromdanaiov1alpha1 "my.domain/githubissue/api/v1alpha1"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+var _ = Describe("GitHubIssue webhooks", func() {
+	ctx := context.Background()
+
+	githubIssue := func(namespace string, name string, repo string, title string) *maromdanaiov1alpha1.GitHubIssue {
+		return &maromdanaiov1alpha1.GitHubIssue{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace},
+			Spec:       maromdanaiov1alpha1.GitHubIssueSpec{Repo: repo, Title: title},
+		}
+	}
+
+	var validator *maromdanaiov1alpha1.GitHubIssueValidator
+
+	BeforeEach(func() {
+		scheme := runtime.NewScheme()
+		Expect(maromdanaiov1alpha1.AddToScheme(scheme)).To(Succeed())
+		validator = &maromdanaiov1alpha1.GitHubIssueValidator{Client: fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+			githubIssue("team-a", "existing", "MaromC/GitHubIssue-Operator", "Existing Issue"),
+		).WithIndex(&maromdanaiov1alpha1.GitHubIssue{}, maromdanaiov1alpha1.IssueKeyField, maromdanaiov1alpha1.IssueKeys).Build()}
+	})
+
+	It("should turn repo urls into their owner/repo and trim the title", func() {
+		issue := githubIssue("default", "url", " https://github.com/MaromC/GitHubIssue-Operator.git/ ", "  Url Issue\n")
+		Expect((&maromdanaiov1alpha1.GitHubIssueDefaulter{}).Default(ctx, issue)).To(Succeed())
+		Expect(issue.Spec.Repo).To(Equal("MaromC/GitHubIssue-Operator"))
+		Expect(issue.Spec.Title).To(Equal("Url Issue"))
+
+		Expect(maromdanaiov1alpha1.NormalizeRepo("MaromC/GitHubIssue-Operator")).To(Equal("MaromC/GitHubIssue-Operator"))
+		Expect(maromdanaiov1alpha1.NormalizeRepo("https://github.example.com/MaromC/GitHubIssue-Operator")).To(Equal("MaromC/GitHubIssue-Operator"))
+	})
+
+	It("should reject invalid repos, missing titles and oversized descriptions", func() {
+		_, err := validator.ValidateCreate(ctx, githubIssue("default", "no-slash", "GitHubIssue-Operator", "No Slash"))
+		Expect(apierrors.IsInvalid(err)).To(BeTrue())
+		Expect(err.Error()).To(ContainSubstring("spec.repo"))
+
+		_, err = validator.ValidateCreate(ctx, githubIssue("default", "no-title", "MaromC/GitHubIssue-Operator", " "))
+		Expect(err).To(MatchError(ContainSubstring("spec.title")))
+
+		templated := githubIssue("default", "templated", "MaromC/GitHubIssue-Operator", "")
+		templated.Spec.Template = &maromdanaiov1alpha1.IssueTemplate{Name: "issue-template", TitleKey: "title"}
+		_, err = validator.ValidateCreate(ctx, templated)
+		Expect(err).NotTo(HaveOccurred())
+
+		oversized := githubIssue("default", "oversized", "MaromC/GitHubIssue-Operator", "Oversized Issue")
+		oversized.Spec.Description = strings.Repeat("a", maromdanaiov1alpha1.MaxDescriptionLength)
+		_, err = validator.ValidateCreate(ctx, oversized)
+		Expect(err).NotTo(HaveOccurred())
+
+		oversized.Spec.Description += "a"
+		_, err = validator.ValidateCreate(ctx, oversized)
+		Expect(err).To(MatchError(ContainSubstring("spec.description")))
+	})
+
+	It("should keep titles unique per repo in the cluster", func() {
+		_, err := validator.ValidateCreate(ctx, githubIssue("team-b", "duplicate", "maromc/githubissue-operator", "Existing Issue"))
+		Expect(err).To(MatchError(ContainSubstring("team-a/existing")))
+
+		_, err = validator.ValidateCreate(ctx, githubIssue("team-b", "other-repo", "MaromC/Other", "Existing Issue"))
+		Expect(err).NotTo(HaveOccurred())
+
+		existing := githubIssue("team-a", "existing", "MaromC/GitHubIssue-Operator", "Existing Issue")
+		_, err = validator.ValidateUpdate(ctx, existing, existing)
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("should keep the repo immutable unless the GitHubIssue is being deleted", func() {
+		oldIssue := githubIssue("default", "moved", "MaromC/GitHubIssue-Operator", "Moved Issue")
+		newIssue := githubIssue("default", "moved", "MaromC/Other", "Moved Issue")
+		_, err := validator.ValidateUpdate(ctx, oldIssue, newIssue)
+		Expect(err).To(MatchError(ContainSubstring("repo is immutable")))
+
+		newIssue.Spec.Repo = "maromc/githubissue-operator"
+		_, err = validator.ValidateUpdate(ctx, oldIssue, newIssue)
+		Expect(err).NotTo(HaveOccurred())
+
+		newIssue.Spec.Repo = "MaromC/Other"
+		newIssue.DeletionTimestamp = &metav1.Time{Time: time.Now()}
+		_, err = validator.ValidateUpdate(ctx, oldIssue, newIssue)
+		Expect(err).NotTo(HaveOccurred())
+	})
+})
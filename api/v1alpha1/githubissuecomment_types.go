@@ -0,0 +1,94 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// GitHubIssueCommentSpec defines the desired state of GitHubIssueComment
+type GitHubIssueCommentSpec struct {
+	// IssueRef is the name of the GitHubIssue, in the same namespace, the comment is posted on
+	// +optional
+	IssueRef string `json:"issueRef,omitempty"`
+	// Repo is the owner/repo of the issue the comment is posted on, used when IssueRef is not set
+	// +kubebuilder:validation:Pattern=`^[a-zA-Z0-9_.-]+/[a-zA-Z0-9_.-]+$`
+	// +optional
+	Repo string `json:"repo,omitempty"`
+	// IssueNumber is the number of the issue the comment is posted on, used when IssueRef is not set
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	IssueNumber int `json:"issueNumber,omitempty"`
+	// Body is the markdown body of the comment, the ownership marker of the GitHubIssueComment is appended to it as a hidden HTML comment
+	// +kubebuilder:validation:MinLength=1
+	Body string `json:"body"`
+	// CredentialsRef references the secret holding the GitHub token used to post the comment.
+	// When it is not set the credentials of the referenced GitHubIssue are used, or the operator wide token.
+	// +optional
+	CredentialsRef *CredentialsRef `json:"credentialsRef,omitempty"`
+}
+
+// GitHubIssueCommentStatus defines the observed state of GitHubIssueComment
+type GitHubIssueCommentStatus struct {
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+	// CommentID is the id of the comment on GitHub, set once the comment has been posted
+	CommentID int64 `json:"commentId,omitempty"`
+	// HTMLURL is the url of the comment on GitHub
+	HTMLURL string `json:"htmlUrl,omitempty"`
+	// Repo is the owner/repo of the issue the comment was posted on
+	Repo string `json:"repo,omitempty"`
+	// IssueNumber is the number of the issue the comment was posted on
+	IssueNumber int `json:"issueNumber,omitempty"`
+}
+
+// CommentRequest defines the structure for the comment request sent
+type CommentRequest struct {
+	Body string `json:"body"`
+}
+
+// CommentResponse defines the structure for the comment given back
+type CommentResponse struct {
+	ID      int64  `json:"id"`
+	NodeID  string `json:"node_id"`
+	HTMLURL string `json:"html_url"`
+	Body    string `json:"body"`
+}
+
+//+kubebuilder:object:root=true
+//+kubebuilder:subresource:status
+
+// GitHubIssueComment is the Schema for the githubissuecomments API
+type GitHubIssueComment struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   GitHubIssueCommentSpec   `json:"spec,omitempty"`
+	Status GitHubIssueCommentStatus `json:"status,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+
+// GitHubIssueCommentList contains a list of GitHubIssueComment
+type GitHubIssueCommentList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []GitHubIssueComment `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&GitHubIssueComment{}, &GitHubIssueCommentList{})
+}
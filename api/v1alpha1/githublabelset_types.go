@@ -0,0 +1,100 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// GitHubLabelSetSpec defines the desired state of GitHubLabelSet
+type GitHubLabelSetSpec struct {
+	// Repo is the owner/repo the labels are managed in
+	// +kubebuilder:validation:Pattern=`^[a-zA-Z0-9_.-]+/[a-zA-Z0-9_.-]+$`
+	Repo string `json:"repo"`
+	// Labels are the labels which must exist in the repo
+	// +listType=map
+	// +listMapKey=name
+	// +optional
+	Labels []LabelSpec `json:"labels,omitempty"`
+	// Prune deletes the labels of the repo which are not in Labels
+	// +optional
+	Prune bool `json:"prune,omitempty"`
+	// CredentialsRef references the secret holding the GitHub token, in the namespace of the GitHubLabelSet.
+	// When it is not set the operator wide github-token secret is used.
+	// +optional
+	CredentialsRef *CredentialsRef `json:"credentialsRef,omitempty"`
+}
+
+// LabelSpec defines a repository label
+type LabelSpec struct {
+	// Name is the name of the label
+	// +kubebuilder:validation:MinLength=1
+	Name string `json:"name"`
+	// Color is the hex color of the label, without the leading #
+	// +kubebuilder:validation:Pattern=`^[0-9a-fA-F]{6}$`
+	Color string `json:"color"`
+	// Description is a short description of the label
+	// +kubebuilder:validation:MaxLength=100
+	// +optional
+	Description string `json:"description,omitempty"`
+	// PreviousNames are former names of the label, an existing label with one of them is renamed instead of a new label created.
+	// Renaming keeps the label on the issues it is set on.
+	// +optional
+	PreviousNames []string `json:"previousNames,omitempty"`
+}
+
+// GitHubLabelSetStatus defines the observed state of GitHubLabelSet
+type GitHubLabelSetStatus struct {
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+	// Labels are the names of the labels managed in the repo
+	Labels []string `json:"labels,omitempty"`
+	// Pruned are the names of the labels deleted from the repo by the last reconcile
+	Pruned []string `json:"pruned,omitempty"`
+}
+
+// LabelRequest defines the structure for the label request sent
+type LabelRequest struct {
+	Name        string `json:"name,omitempty"`
+	NewName     string `json:"new_name,omitempty"`
+	Color       string `json:"color,omitempty"`
+	Description string `json:"description"`
+}
+
+//+kubebuilder:object:root=true
+//+kubebuilder:subresource:status
+
+// GitHubLabelSet is the Schema for the githublabelsets API
+type GitHubLabelSet struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   GitHubLabelSetSpec   `json:"spec,omitempty"`
+	Status GitHubLabelSetStatus `json:"status,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+
+// GitHubLabelSetList contains a list of GitHubLabelSet
+type GitHubLabelSetList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []GitHubLabelSet `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&GitHubLabelSet{}, &GitHubLabelSetList{})
+}
@@ -0,0 +1,94 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// GitHubMilestoneSpec defines the desired state of GitHubMilestone
+type GitHubMilestoneSpec struct {
+	// Repo is the owner/repo the milestone is managed in
+	// +kubebuilder:validation:Pattern=`^[a-zA-Z0-9_.-]+/[a-zA-Z0-9_.-]+$`
+	Repo string `json:"repo"`
+	// Title is the title of the milestone, an existing milestone with this title is adopted
+	// +kubebuilder:validation:MinLength=1
+	Title string `json:"title"`
+	// Description is the description of the milestone
+	// +optional
+	Description string `json:"description,omitempty"`
+	// DueOn is the due date of the milestone, GitHub only keeps its day
+	// +optional
+	DueOn *metav1.Time `json:"dueOn,omitempty"`
+	// State is the desired state of the milestone
+	// +kubebuilder:validation:Enum=open;closed
+	// +kubebuilder:default=open
+	// +optional
+	State string `json:"state,omitempty"`
+	// CredentialsRef references the secret holding the GitHub token, in the namespace of the GitHubMilestone.
+	// When it is not set the operator wide github-token secret is used.
+	// +optional
+	CredentialsRef *CredentialsRef `json:"credentialsRef,omitempty"`
+}
+
+// GitHubMilestoneStatus defines the observed state of GitHubMilestone
+type GitHubMilestoneStatus struct {
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+	// Number is the number of the milestone on GitHub
+	Number int `json:"number,omitempty"`
+	// HTMLURL is the link to the milestone on GitHub
+	HTMLURL string `json:"htmlUrl,omitempty"`
+	// State is the state of the milestone on GitHub
+	State string `json:"state,omitempty"`
+	// OpenIssues is the number of open issues in the milestone
+	OpenIssues int `json:"openIssues,omitempty"`
+	// ClosedIssues is the number of closed issues in the milestone
+	ClosedIssues int `json:"closedIssues,omitempty"`
+}
+
+// MilestoneRequest defines the structure for the milestone request sent
+type MilestoneRequest struct {
+	Title       string       `json:"title"`
+	State       string       `json:"state,omitempty"`
+	Description string       `json:"description"`
+	DueOn       *metav1.Time `json:"due_on"`
+}
+
+//+kubebuilder:object:root=true
+//+kubebuilder:subresource:status
+
+// GitHubMilestone is the Schema for the githubmilestones API
+type GitHubMilestone struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   GitHubMilestoneSpec   `json:"spec,omitempty"`
+	Status GitHubMilestoneStatus `json:"status,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+
+// GitHubMilestoneList contains a list of GitHubMilestone
+type GitHubMilestoneList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []GitHubMilestone `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&GitHubMilestone{}, &GitHubMilestoneList{})
+}
@@ -0,0 +1,285 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// GitHubIssueSpec defines the desired state of GitHubIssue
+type GitHubIssueSpec struct {
+	// Repo is the owner/repo of the issue, a repo url is turned into its owner/repo by the defaulting webhook
+	// +kubebuilder:validation:Pattern=`^[a-zA-Z0-9_.-]+/[a-zA-Z0-9_.-]+$`
+	Repo string `json:"repo"`
+	// Title is the title of the issue, it may be left empty when it is rendered from the template
+	// +optional
+	Title string `json:"title,omitempty"`
+	// Description is the body of the issue
+	// +optional
+	Description string `json:"description,omitempty"`
+	// Template renders the description, and the title when TitleKey is set, from a ConfigMap.
+	// The rendered text replaces the title and description of the spec.
+	// +optional
+	Template *IssueTemplate `json:"template,omitempty"`
+	// Values are the values given to the template as .Values
+	// +optional
+	Values map[string]string `json:"values,omitempty"`
+	// Labels are the names of the labels set on the issue
+	// +optional
+	Labels []string `json:"labels,omitempty"`
+	// Assignees are the logins of the users assigned to the issue
+	// +optional
+	Assignees []string `json:"assignees,omitempty"`
+	// Milestone is the milestone the issue belongs to
+	// +optional
+	Milestone *MilestoneReference `json:"milestone,omitempty"`
+	// State is the desired state of the issue
+	// +kubebuilder:default=open
+	// +optional
+	State IssueState `json:"state,omitempty"`
+	// StateReason is the reason the issue is closed for, it is only used when the state is closed
+	// +optional
+	StateReason StateReason `json:"stateReason,omitempty"`
+	// StatePolicy decides what happens when the issue is closed on GitHub while the desired state is open.
+	// Enforce reopens the issue, RespectRemote leaves it closed.
+	// +kubebuilder:default=Enforce
+	// +optional
+	StatePolicy StatePolicy `json:"statePolicy,omitempty"`
+	// DeletionPolicy decides what happens to the issue when the GitHubIssue is deleted
+	// +kubebuilder:default=Close
+	// +optional
+	DeletionPolicy DeletionPolicy `json:"deletionPolicy,omitempty"`
+	// DeletionComment is a comment posted on the issue before the deletion policy is applied
+	// +optional
+	DeletionComment string `json:"deletionComment,omitempty"`
+	// TransferTo is the repo the issue is transferred to when the deletion policy is Transfer
+	// +kubebuilder:validation:Pattern=`^[a-zA-Z0-9_.-]+/[a-zA-Z0-9_.-]+$`
+	// +optional
+	TransferTo string `json:"transferTo,omitempty"`
+	// SyncPolicy decides what happens when the issue is edited on GitHub.
+	// Enforce corrects the issue back to the spec, ObserveOnly only reports the drift.
+	// +kubebuilder:default=Enforce
+	// +optional
+	SyncPolicy SyncPolicy `json:"syncPolicy,omitempty"`
+	// Adopt allows adopting an existing issue with the same title which was not created for this GitHubIssue.
+	// Issues created by the operator are marked as owned, and only the marked issues are adopted otherwise.
+	// +optional
+	Adopt bool `json:"adopt,omitempty"`
+	// CredentialsRef references the secret holding the GitHub token, in the namespace of the GitHubIssue.
+	// When it is not set the operator wide github-token secret is used.
+	// The GitHub API url and CA bundle are read from the apiUrl and caBundle keys of the secret,
+	// falling back to the operator flags, they can't be set on the GitHubIssue itself.
+	// +optional
+	CredentialsRef *CredentialsRef `json:"credentialsRef,omitempty"`
+}
+
+// MilestoneReference references the milestone of an issue, by its number or by the GitHubMilestone managing it
+// +kubebuilder:validation:MinProperties=1
+type MilestoneReference struct {
+	// Number is the number of the milestone in the repo of the issue
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	Number int `json:"number,omitempty"`
+	// Name is the name of a GitHubMilestone in the namespace of the GitHubIssue, it takes precedence over Number
+	// +optional
+	Name string `json:"name,omitempty"`
+}
+
+// IssueTemplate references the Go text/template templates of an issue in a ConfigMap in the namespace of the GitHubIssue.
+// Templates are given the name, namespace, labels and annotations of the GitHubIssue, its spec and the values.
+type IssueTemplate struct {
+	// Name is the name of the ConfigMap
+	// +kubebuilder:validation:MinLength=1
+	Name string `json:"name"`
+	// Key is the key of the description template in the ConfigMap
+	// +kubebuilder:default=body
+	// +optional
+	Key string `json:"key,omitempty"`
+	// TitleKey is the key of the title template in the ConfigMap, the title of the spec is kept when it is not set
+	// +optional
+	TitleKey string `json:"titleKey,omitempty"`
+}
+
+// CredentialsRef references a key of a secret in the namespace of the GitHubIssue
+type CredentialsRef struct {
+	// Name is the name of the secret
+	// +kubebuilder:validation:MinLength=1
+	Name string `json:"name"`
+	// Key is the key of the GitHub token in the secret, it is not used for GitHub App credentials
+	// +kubebuilder:default=token
+	// +optional
+	Key string `json:"key,omitempty"`
+	// Type is the type of the credentials held by the secret.
+	// Token secrets hold a token under Key, GitHubApp secrets hold the appId, installationId and privateKey keys.
+	// +kubebuilder:default=Token
+	// +optional
+	Type CredentialsType `json:"type,omitempty"`
+}
+
+// IssueState defines the state of an issue
+// +kubebuilder:validation:Enum=open;closed
+type IssueState string
+
+const (
+	// IssueStateOpen is an open issue
+	IssueStateOpen IssueState = "open"
+	// IssueStateClosed is a closed issue
+	IssueStateClosed IssueState = "closed"
+)
+
+// StateReason defines the reason an issue is closed for
+// +kubebuilder:validation:Enum=completed;not_planned
+type StateReason string
+
+const (
+	// StateReasonCompleted closes the issue as completed
+	StateReasonCompleted StateReason = "completed"
+	// StateReasonNotPlanned closes the issue as not planned
+	StateReasonNotPlanned StateReason = "not_planned"
+)
+
+// CredentialsType defines the type of the credentials held by a secret
+// +kubebuilder:validation:Enum=Token;GitHubApp
+type CredentialsType string
+
+const (
+	// CredentialsTypeToken is a personal access token
+	CredentialsTypeToken CredentialsType = "Token"
+	// CredentialsTypeGitHubApp is a GitHub App installation
+	CredentialsTypeGitHubApp CredentialsType = "GitHubApp"
+)
+
+// StatePolicy defines what happens to an issue closed on GitHub while the desired state is open
+// +kubebuilder:validation:Enum=Enforce;RespectRemote
+type StatePolicy string
+
+const (
+	// StatePolicyEnforce reopens issues closed on GitHub
+	StatePolicyEnforce StatePolicy = "Enforce"
+	// StatePolicyRespectRemote leaves issues closed on GitHub closed
+	StatePolicyRespectRemote StatePolicy = "RespectRemote"
+)
+
+// DeletionPolicy defines what happens to an issue when its GitHubIssue is deleted
+// +kubebuilder:validation:Enum=Close;CloseNotPlanned;Lock;Orphan;Transfer
+type DeletionPolicy string
+
+const (
+	// DeletionPolicyClose closes the issue as completed
+	DeletionPolicyClose DeletionPolicy = "Close"
+	// DeletionPolicyCloseNotPlanned closes the issue as not planned
+	DeletionPolicyCloseNotPlanned DeletionPolicy = "CloseNotPlanned"
+	// DeletionPolicyLock closes the issue and locks its conversation
+	DeletionPolicyLock DeletionPolicy = "Lock"
+	// DeletionPolicyOrphan leaves the issue untouched
+	DeletionPolicyOrphan DeletionPolicy = "Orphan"
+	// DeletionPolicyTransfer transfers the issue to the TransferTo repo
+	DeletionPolicyTransfer DeletionPolicy = "Transfer"
+)
+
+// SyncPolicy defines what happens to an issue edited on GitHub
+// +kubebuilder:validation:Enum=Enforce;ObserveOnly
+type SyncPolicy string
+
+const (
+	// SyncPolicyEnforce corrects the issue back to the spec
+	SyncPolicyEnforce SyncPolicy = "Enforce"
+	// SyncPolicyObserveOnly only reports the drift of the issue from the spec
+	SyncPolicyObserveOnly SyncPolicy = "ObserveOnly"
+)
+
+// GitHubIssueStatus defines the observed state of GitHubIssue
+type GitHubIssueStatus struct {
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+	// Number is the number of the issue on GitHub, set once the issue has been created or adopted
+	Number int `json:"number,omitempty"`
+	// NodeID is the GraphQL node id of the issue on GitHub
+	NodeID string `json:"nodeId,omitempty"`
+	// HTMLURL is the url of the issue page on GitHub
+	HTMLURL string `json:"htmlUrl,omitempty"`
+	// Title is the current title of the issue on GitHub
+	Title string `json:"title,omitempty"`
+	// Labels are the names of the labels currently set on the issue
+	Labels []string `json:"labels,omitempty"`
+	// Assignees are the logins of the users currently assigned to the issue
+	Assignees []string `json:"assignees,omitempty"`
+	// Milestone is the number of the milestone the issue currently belongs to
+	Milestone int `json:"milestone,omitempty"`
+	// State is the current state of the issue on GitHub
+	State IssueState `json:"state,omitempty"`
+	// StateReason is the reason for the current state of the issue on GitHub
+	StateReason string `json:"stateReason,omitempty"`
+	// DriftedFields are the fields of the issue on GitHub which differ from the spec
+	DriftedFields []string `json:"driftedFields,omitempty"`
+	// PullRequests are the pull requests referencing the issue
+	PullRequests []LinkedPullRequest `json:"pullRequests,omitempty"`
+	// PullRequestCount is the number of pull requests referencing the issue
+	PullRequestCount int `json:"pullRequestCount,omitempty"`
+	// LastSyncedTime is the last time the issue was successfully synced with GitHub,
+	// syncs which neither write to the issue nor find it changed leave it as is
+	LastSyncedTime *metav1.Time `json:"lastSyncedTime,omitempty"`
+	// ObservedGeneration is the generation of the spec the issue was last synced with
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+}
+
+// LinkedPullRequest is a pull request referencing the issue
+type LinkedPullRequest struct {
+	// Repo is the owner/repo of the pull request
+	Repo string `json:"repo"`
+	// Number is the number of the pull request
+	Number int `json:"number"`
+	// URL is the url of the pull request page on GitHub
+	URL string `json:"url,omitempty"`
+	// State is the state of the pull request, open or closed
+	State string `json:"state,omitempty"`
+	// Merged is true once the pull request has been merged
+	Merged bool `json:"merged,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+//+kubebuilder:subresource:status
+//+kubebuilder:storageversion
+//+kubebuilder:printcolumn:name="Repo",type=string,JSONPath=`.spec.repo`
+//+kubebuilder:printcolumn:name="Number",type=integer,JSONPath=`.status.number`
+//+kubebuilder:printcolumn:name="State",type=string,JSONPath=`.status.state`
+//+kubebuilder:printcolumn:name="PRs",type=integer,JSONPath=`.status.pullRequestCount`
+//+kubebuilder:printcolumn:name="Ready",type=string,JSONPath=`.status.conditions[?(@.type=="Ready")].status`
+//+kubebuilder:printcolumn:name="URL",type=string,JSONPath=`.status.htmlUrl`,priority=1
+//+kubebuilder:printcolumn:name="Last Synced",type=date,JSONPath=`.status.lastSyncedTime`,priority=1
+//+kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
+
+// GitHubIssue is the Schema for the githubissues API
+type GitHubIssue struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   GitHubIssueSpec   `json:"spec,omitempty"`
+	Status GitHubIssueStatus `json:"status,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+
+// GitHubIssueList contains a list of GitHubIssue
+type GitHubIssueList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []GitHubIssue `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&GitHubIssue{}, &GitHubIssueList{})
+}
@@ -17,8 +17,11 @@ limitations under the License.
 package main
 
 import (
+	"bytes"
 	"crypto/tls"
+	"errors"
 	"flag"
+	"fmt"
 	"os"
 	"time"
 
@@ -38,7 +41,11 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/webhook"
 
 	maromdanaiov1alpha1 "my.domain/githubissue/api/v1alpha1"
+	maromdanaiov1beta1 "my.domain/githubissue/api/v1beta1"
+	"my.domain/githubissue/internal/clients/git"
+	httpClient "my.domain/githubissue/internal/clients/http"
 	"my.domain/githubissue/internal/controller"
+	"my.domain/githubissue/internal/receiver"
 	//+kubebuilder:scaffold:imports
 )
 
@@ -51,6 +58,7 @@ func init() {
 	utilruntime.Must(clientgoscheme.AddToScheme(scheme))
 
 	utilruntime.Must(maromdanaiov1alpha1.AddToScheme(scheme))
+	utilruntime.Must(maromdanaiov1beta1.AddToScheme(scheme))
 	//+kubebuilder:scaffold:scheme
 }
 
@@ -61,6 +69,13 @@ func main() {
 	var secureMetrics bool
 	var enableHTTP2 bool
 	var syncPeriod time.Duration
+	var githubAPIURL string
+	var githubCABundle string
+	var githubAllowInsecureHTTP bool
+	var githubCacheSize int
+	var githubWebhookAddr string
+	var githubWebhookSecret string
+	var clusterID string
 	flag.StringVar(&metricsAddr, "metrics-bind-address", ":8080", "The address the metric endpoint binds to.")
 	flag.StringVar(&probeAddr, "health-probe-bind-address", ":8081", "The address the probe endpoint binds to.")
 	flag.BoolVar(&enableLeaderElection, "leader-elect", false,
@@ -71,6 +86,21 @@ func main() {
 	flag.BoolVar(&enableHTTP2, "enable-http2", false,
 		"If set, HTTP/2 will be enabled for the metrics and webhook servers")
 	flag.DurationVar(&syncPeriod, "sync-period", time.Minute, "The sync period for the controller manager.")
+	flag.StringVar(&githubAPIURL, "github-api-url", git.APIBaseURL,
+		"The GitHub API url, set it to the url of a GitHub Enterprise Server to use it by default. "+
+			"Credentials secrets can override it with the apiUrl key.")
+	flag.StringVar(&githubCABundle, "github-ca-bundle", "",
+		"The path to a PEM encoded CA bundle trusted when talking to the GitHub API.")
+	flag.BoolVar(&githubAllowInsecureHTTP, "github-allow-insecure-http", false,
+		"If set, http GitHub API urls are allowed, the credentials are then sent in plaintext.")
+	flag.IntVar(&githubCacheSize, "github-cache-size", httpClient.DefaultCacheSize,
+		"The number of bytes of GitHub responses cached for conditional requests, 0 disables the cache.")
+	flag.StringVar(&githubWebhookAddr, "github-webhook-bind-address", "",
+		"The address the GitHub webhook receiver binds to, the receiver is disabled when it is empty.")
+	flag.StringVar(&githubWebhookSecret, "github-webhook-secret", "",
+		"The path to the file holding the secret GitHub webhook deliveries are signed with.")
+	flag.StringVar(&clusterID, "cluster-id", "",
+		"The id of the cluster written in the ownership marker of the issues, at most 63 characters, set it when several clusters manage the same repos.")
 	opts := zap.Options{
 		Development: true,
 	}
@@ -79,6 +109,32 @@ func main() {
 
 	ctrl.SetLogger(zap.New(zap.UseFlagOptions(&opts)))
 
+	git.AllowInsecureHTTP = githubAllowInsecureHTTP
+	baseURL, err := git.ValidateBaseURL(githubAPIURL)
+	if err != nil {
+		setupLog.Error(err, "invalid GitHub API url")
+		os.Exit(1)
+	}
+	git.APIBaseURL = baseURL
+	if len(clusterID) > maromdanaiov1alpha1.MaxClusterIDLength {
+		setupLog.Error(fmt.Errorf("cluster id has %d characters, more than the %d allowed", len(clusterID), maromdanaiov1alpha1.MaxClusterIDLength),
+			"invalid cluster id")
+		os.Exit(1)
+	}
+	if githubCABundle != "" {
+		caBundle, err := os.ReadFile(githubCABundle)
+		if err != nil {
+			setupLog.Error(err, "unable to read GitHub CA bundle")
+			os.Exit(1)
+		}
+		if err := git.ValidateCABundle(caBundle); err != nil {
+			setupLog.Error(err, "invalid GitHub CA bundle")
+			os.Exit(1)
+		}
+		git.CABundle = caBundle
+	}
+	httpClient.SetCacheSize(githubCacheSize)
+
 	// if the enable-http2 flag is false (the default), http/2 should be disabled
 	// due to its vulnerabilities. More specifically, disabling http/2 will
 	// prevent from being vulnerable to the HTTP/2 Stream Cancellation and
@@ -130,14 +186,77 @@ func main() {
 		os.Exit(1)
 	}
 
+	if githubWebhookAddr != "" {
+		secret, err := os.ReadFile(githubWebhookSecret)
+		if err != nil {
+			setupLog.Error(err, "unable to read GitHub webhook secret")
+			os.Exit(1)
+		}
+		secret = bytes.TrimSpace(secret)
+		if len(secret) == 0 {
+			setupLog.Error(errors.New("the secret is empty"), "invalid GitHub webhook secret")
+			os.Exit(1)
+		}
+		if err := mgr.Add(&receiver.Receiver{
+			Client:  mgr.GetClient(),
+			Secret:  secret,
+			Address: githubWebhookAddr,
+			Logger:  ctrl.Log.WithName("receiver"),
+		}); err != nil {
+			setupLog.Error(err, "unable to set up GitHub webhook receiver")
+			os.Exit(1)
+		}
+	}
+
 	if err = (&controller.GitHubIssueReconciler{
-		Client: mgr.GetClient(),
-		Scheme: mgr.GetScheme(),
-		Logger: ctrl.Log.WithName("controllers").WithName("GitHubIssue"),
+		Client:    mgr.GetClient(),
+		Scheme:    mgr.GetScheme(),
+		Logger:    ctrl.Log.WithName("controllers").WithName("GitHubIssue"),
+		APIReader: mgr.GetAPIReader(),
+		Recorder:  mgr.GetEventRecorderFor("githubissue-controller"),
+		ClusterID: clusterID,
 	}).SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "GitHubIssue")
 		os.Exit(1)
 	}
+	if err = (&controller.GitHubIssueCommentReconciler{
+		Client:    mgr.GetClient(),
+		Scheme:    mgr.GetScheme(),
+		Logger:    ctrl.Log.WithName("controllers").WithName("GitHubIssueComment"),
+		APIReader: mgr.GetAPIReader(),
+		ClusterID: clusterID,
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "GitHubIssueComment")
+		os.Exit(1)
+	}
+	if err = (&controller.GitHubLabelSetReconciler{
+		Client:    mgr.GetClient(),
+		Scheme:    mgr.GetScheme(),
+		Logger:    ctrl.Log.WithName("controllers").WithName("GitHubLabelSet"),
+		APIReader: mgr.GetAPIReader(),
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "GitHubLabelSet")
+		os.Exit(1)
+	}
+	if err = (&controller.GitHubMilestoneReconciler{
+		Client:    mgr.GetClient(),
+		Scheme:    mgr.GetScheme(),
+		Logger:    ctrl.Log.WithName("controllers").WithName("GitHubMilestone"),
+		APIReader: mgr.GetAPIReader(),
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "GitHubMilestone")
+		os.Exit(1)
+	}
+	if os.Getenv("ENABLE_WEBHOOKS") != "false" {
+		if err = (&maromdanaiov1alpha1.GitHubIssue{}).SetupWebhookWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create webhook", "webhook", "GitHubIssue")
+			os.Exit(1)
+		}
+	}
+	if err := controller.RegisterIssueCollector(mgr.GetClient(), ctrl.Log.WithName("metrics")); err != nil {
+		setupLog.Error(err, "unable to register managed issues metrics")
+		os.Exit(1)
+	}
 	//+kubebuilder:scaffold:builder
 
 	if err := mgr.AddHealthzCheck("healthz", healthz.Ping); err != nil {
@@ -0,0 +1,236 @@
+package git
+
+import (
+	"container/list"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/oauth2"
+)
+
+var (
+	appIDKey          = "appId"
+	installationIDKey = "installationId"
+	privateKeyKey     = "privateKey"
+	accessTokensUrl   = "%s/app/installations/%s/access_tokens"
+	// jwtLifetime is kept under the 10 minutes GitHub allows for app JWTs
+	jwtLifetime = 9 * time.Minute
+	// jwtClockSkew backdates the JWT issue time, as GitHub recommends, to allow for clock drift
+	jwtClockSkew = time.Minute
+	// tokenExpiryDelta refreshes installation tokens this long before they expire
+	tokenExpiryDelta = 5 * time.Minute
+	// maxAppTokenSources is the number of installations whose token sources are kept
+	maxAppTokenSources = 128
+
+	appTokenSources = newTokenSourceCache(maxAppTokenSources)
+)
+
+// appCredentials holds what is needed to authenticate as a GitHub App installation.
+type appCredentials struct {
+	AppID          string
+	InstallationID string
+	PrivateKey     []byte
+}
+
+// appCredentialsFromSecret reads the GitHub App credentials from the secret data.
+func appCredentialsFromSecret(data map[string][]byte) (*appCredentials, error) {
+	credentials := &appCredentials{
+		AppID:          string(data[appIDKey]),
+		InstallationID: string(data[installationIDKey]),
+		PrivateKey:     data[privateKeyKey],
+	}
+	for key, value := range map[string]string{appIDKey: credentials.AppID, installationIDKey: credentials.InstallationID, privateKeyKey: string(credentials.PrivateKey)} {
+		if strings.TrimSpace(value) == "" {
+			return nil, fmt.Errorf("%w: key %q", ErrSecretKeyNotFound, key)
+		}
+	}
+	return credentials, nil
+}
+
+// newAppTokenSource returns a token source minting installation tokens for the GitHub App.
+// Token sources are shared between reconciles, so an installation token is reused until it is about to expire.
+func newAppTokenSource(githubEndpoint endpoint, credentials *appCredentials) (oauth2.TokenSource, error) {
+	privateKey, err := parsePrivateKey(credentials.PrivateKey)
+	if err != nil {
+		return nil, err
+	}
+	client, err := githubEndpoint.httpClient()
+	if err != nil {
+		return nil, err
+	}
+
+	cacheKey := fmt.Sprintf("%s|%s|%s", githubEndpoint.key(), credentials.AppID, credentials.InstallationID)
+	keyHash := sha256.Sum256(credentials.PrivateKey)
+	return appTokenSources.getOrAdd(cacheKey, keyHash, func() oauth2.TokenSource {
+		return oauth2.ReuseTokenSourceWithExpiry(nil, &appTokenSource{
+			baseURL:        githubEndpoint.baseURL,
+			appID:          credentials.AppID,
+			installationID: credentials.InstallationID,
+			privateKey:     privateKey,
+			client:         client,
+		}, tokenExpiryDelta)
+	}), nil
+}
+
+// tokenSourceCache is a least recently used cache of the token sources of the GitHub App installations.
+// A token source is replaced when the private key of its installation is rotated, so rotated keys are not kept.
+type tokenSourceCache struct {
+	mutex      sync.Mutex
+	maxEntries int
+	entries    map[string]*list.Element
+	order      *list.List
+}
+
+// cachedTokenSource is the token source of an installation, with the hash of the private key it signs with.
+type cachedTokenSource struct {
+	key         string
+	keyHash     [sha256.Size]byte
+	tokenSource oauth2.TokenSource
+}
+
+func newTokenSourceCache(maxEntries int) *tokenSourceCache {
+	return &tokenSourceCache{
+		maxEntries: maxEntries,
+		entries:    map[string]*list.Element{},
+		order:      list.New(),
+	}
+}
+
+// getOrAdd returns the token source cached for the installation key and private key hash,
+// or caches the one returned by create, evicting the least recently used token sources to stay under the maximum.
+func (c *tokenSourceCache) getOrAdd(key string, keyHash [sha256.Size]byte, create func() oauth2.TokenSource) oauth2.TokenSource {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if element, ok := c.entries[key]; ok {
+		entry := element.Value.(*cachedTokenSource)
+		if entry.keyHash == keyHash {
+			c.order.MoveToFront(element)
+			return entry.tokenSource
+		}
+		c.remove(element)
+	}
+
+	entry := &cachedTokenSource{key: key, keyHash: keyHash, tokenSource: create()}
+	c.entries[key] = c.order.PushFront(entry)
+	for c.order.Len() > c.maxEntries {
+		c.remove(c.order.Back())
+	}
+	return entry.tokenSource
+}
+
+// remove removes the element from the cache.
+func (c *tokenSourceCache) remove(element *list.Element) {
+	entry := c.order.Remove(element).(*cachedTokenSource)
+	delete(c.entries, entry.key)
+}
+
+// appTokenSource exchanges a JWT signed with the GitHub App private key for an installation token.
+type appTokenSource struct {
+	baseURL        string
+	appID          string
+	installationID string
+	privateKey     *rsa.PrivateKey
+	client         *http.Client
+}
+
+// installationToken defines the structure for the installation token given back
+type installationToken struct {
+	Token     string    `json:"token"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// Token mints a new installation token.
+func (s *appTokenSource) Token() (*oauth2.Token, error) {
+	jwt, err := signJWT(s.appID, s.privateKey, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest(http.MethodPost, fmt.Sprintf(accessTokensUrl, s.baseURL, s.installationID), nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/vnd.github.v3+json")
+	req.Header.Set("Authorization", "Bearer "+jwt)
+
+	response, err := s.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusCreated {
+		return nil, fmt.Errorf("failed to mint an installation token for app %s with status code: %d", s.appID, response.StatusCode)
+	}
+
+	var token installationToken
+	if err := json.NewDecoder(response.Body).Decode(&token); err != nil {
+		return nil, err
+	}
+
+	return &oauth2.Token{
+		AccessToken: token.Token,
+		TokenType:   "Bearer",
+		Expiry:      token.ExpiresAt,
+	}, nil
+}
+
+// signJWT returns a RS256 JWT identifying the GitHub App.
+func signJWT(appID string, privateKey *rsa.PrivateKey, now time.Time) (string, error) {
+	header, err := json.Marshal(map[string]string{"alg": "RS256", "typ": "JWT"})
+	if err != nil {
+		return "", err
+	}
+	claims, err := json.Marshal(map[string]interface{}{
+		"iat": now.Add(-jwtClockSkew).Unix(),
+		"exp": now.Add(jwtLifetime).Unix(),
+		"iss": appID,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	unsigned := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(claims)
+	hash := sha256.Sum256([]byte(unsigned))
+	signature, err := rsa.SignPKCS1v15(rand.Reader, privateKey, crypto.SHA256, hash[:])
+	if err != nil {
+		return "", err
+	}
+
+	return unsigned + "." + base64.RawURLEncoding.EncodeToString(signature), nil
+}
+
+// parsePrivateKey parses a PEM encoded PKCS1 or PKCS8 RSA private key.
+func parsePrivateKey(data []byte) (*rsa.PrivateKey, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("GitHub App private key is not PEM encoded")
+	}
+
+	if key, err := x509.ParsePKCS1PrivateKey(block.Bytes); err == nil {
+		return key, nil
+	}
+
+	key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("unable to parse GitHub App private key: %w", err)
+	}
+	rsaKey, ok := key.(*rsa.PrivateKey)
+	if !ok {
+		return nil, errors.New("GitHub App private key is not an RSA key")
+	}
+	return rsaKey, nil
+}
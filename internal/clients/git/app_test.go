@@ -0,0 +1,131 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package git
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"golang.org/x/oauth2"
+)
+
+var _ = Describe("GitHub App authentication", func() {
+	var (
+		server     *httptest.Server
+		mintCalls  int
+		privateKey []byte
+	)
+
+	BeforeEach(func() {
+		key, err := rsa.GenerateKey(rand.Reader, 2048)
+		Expect(err).NotTo(HaveOccurred())
+		privateKey = pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)})
+
+		mintCalls = 0
+		By("Setting up a stand-in installation token endpoint")
+		server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Method != http.MethodPost || r.URL.Path != "/app/installations/42/access_tokens" ||
+				!strings.HasPrefix(r.Header.Get("Authorization"), "Bearer ") {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			mintCalls++
+			w.WriteHeader(http.StatusCreated)
+			_ = json.NewEncoder(w).Encode(installationToken{Token: "ghs_installation", ExpiresAt: time.Now().Add(time.Hour)})
+		}))
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	It("should mint an installation token once and reuse it until it is about to expire", func() {
+		credentials, err := appCredentialsFromSecret(map[string][]byte{
+			appIDKey:          []byte("1"),
+			installationIDKey: []byte("42"),
+			privateKeyKey:     privateKey,
+		})
+		Expect(err).NotTo(HaveOccurred())
+
+		tokenSource, err := newAppTokenSource(endpoint{baseURL: server.URL}, credentials)
+		Expect(err).NotTo(HaveOccurred())
+
+		for i := 0; i < 3; i++ {
+			token, err := tokenSource.Token()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(token.AccessToken).To(Equal("ghs_installation"))
+		}
+
+		By("Initializing the token source again for the same installation")
+		sharedTokenSource, err := newAppTokenSource(endpoint{baseURL: server.URL}, credentials)
+		Expect(err).NotTo(HaveOccurred())
+		_, err = sharedTokenSource.Token()
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(mintCalls).To(Equal(1))
+	})
+
+	It("should replace the token source of an installation when its private key is rotated", func() {
+		credentials := &appCredentials{AppID: "1", InstallationID: "42", PrivateKey: privateKey}
+		tokenSource, err := newAppTokenSource(endpoint{baseURL: server.URL}, credentials)
+		Expect(err).NotTo(HaveOccurred())
+		_, err = tokenSource.Token()
+		Expect(err).NotTo(HaveOccurred())
+
+		By("Rotating the private key of the installation")
+		key, err := rsa.GenerateKey(rand.Reader, 2048)
+		Expect(err).NotTo(HaveOccurred())
+		rotated := &appCredentials{AppID: "1", InstallationID: "42", PrivateKey: pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)})}
+		rotatedTokenSource, err := newAppTokenSource(endpoint{baseURL: server.URL}, rotated)
+		Expect(err).NotTo(HaveOccurred())
+		_, err = rotatedTokenSource.Token()
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(mintCalls).To(Equal(2))
+		Expect(appTokenSources.entries).To(HaveKey(endpoint{baseURL: server.URL}.key() + "|1|42"))
+	})
+
+	It("should evict the least recently used token sources", func() {
+		cache := newTokenSourceCache(2)
+		hash := sha256.Sum256(privateKey)
+		create := func() oauth2.TokenSource { return oauth2.StaticTokenSource(&oauth2.Token{AccessToken: "token"}) }
+
+		cache.getOrAdd("first", hash, create)
+		cache.getOrAdd("second", hash, create)
+		cache.getOrAdd("first", hash, create)
+		cache.getOrAdd("third", hash, create)
+
+		Expect(cache.entries).To(HaveLen(2))
+		Expect(cache.entries).To(HaveKey("first"))
+		Expect(cache.entries).To(HaveKey("third"))
+	})
+
+	It("should report missing GitHub App keys in the secret", func() {
+		_, err := appCredentialsFromSecret(map[string][]byte{appIDKey: []byte("1")})
+		Expect(err).To(MatchError(ErrSecretKeyNotFound))
+	})
+})
@@ -0,0 +1,152 @@
+package git
+
+import (
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net/http"
+	neturl "net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+var (
+	// CABundle is the PEM encoded CA bundle trusted, on top of the system CAs, when talking to APIBaseURL
+	CABundle []byte
+	// AllowInsecureHTTP allows http GitHub API urls, which send the tokens and app JWTs in plaintext
+	AllowInsecureHTTP bool
+
+	apiURLKey             = "apiUrl"
+	caBundleKey           = "caBundle"
+	publicAPIHost         = "api.github.com"
+	enterpriseRESTPath    = "/api/v3"
+	enterpriseGraphQLPath = "/api/graphql"
+	requestTimeout        = 30 * time.Second
+	graphqlPath           = "/graphql"
+
+	transportsMutex sync.Mutex
+	transports      = map[string]*endpointTransport{}
+)
+
+// ErrInvalidEndpoint is returned when the GitHub API url or CA bundle can't be used
+var ErrInvalidEndpoint = errors.New("invalid GitHub API endpoint")
+
+// endpoint is the GitHub API the clients talk to, either github.com or a GitHub Enterprise Server.
+type endpoint struct {
+	baseURL  string
+	caBundle []byte
+}
+
+// defaultEndpoint returns the operator wide endpoint.
+func defaultEndpoint() endpoint {
+	return endpoint{baseURL: APIBaseURL, caBundle: CABundle}
+}
+
+// endpointFromSecret returns the endpoint set in the credentials secret, falling back to the operator wide endpoint.
+func endpointFromSecret(data map[string][]byte) (endpoint, error) {
+	result := defaultEndpoint()
+	if apiURL, ok := data[apiURLKey]; ok {
+		baseURL, err := ValidateBaseURL(string(apiURL))
+		if err != nil {
+			return endpoint{}, err
+		}
+		result.baseURL = baseURL
+	}
+	if caBundle, ok := data[caBundleKey]; ok {
+		result.caBundle = caBundle
+	}
+	return result, nil
+}
+
+// ValidateBaseURL validates the GitHub API url and returns it in the form the url templates expect.
+// GitHub Enterprise Server urls without a path get the /api/v3 prefix their REST API is served under.
+func ValidateBaseURL(raw string) (string, error) {
+	parsed, err := neturl.Parse(strings.TrimSpace(raw))
+	if err != nil {
+		return "", fmt.Errorf("%w: %s", ErrInvalidEndpoint, err.Error())
+	}
+	if parsed.Scheme == "http" && !AllowInsecureHTTP {
+		return "", fmt.Errorf("%w: %q must be an https url, http urls send the credentials in plaintext", ErrInvalidEndpoint, raw)
+	}
+	if parsed.Scheme != "https" && parsed.Scheme != "http" {
+		return "", fmt.Errorf("%w: %q must be an https url", ErrInvalidEndpoint, raw)
+	}
+	if parsed.Host == "" {
+		return "", fmt.Errorf("%w: %q has no host", ErrInvalidEndpoint, raw)
+	}
+	if parsed.RawQuery != "" || parsed.Fragment != "" {
+		return "", fmt.Errorf("%w: %q must not have a query or fragment", ErrInvalidEndpoint, raw)
+	}
+
+	parsed.Path = strings.TrimRight(parsed.Path, "/")
+	if parsed.Path == "" && parsed.Host != publicAPIHost {
+		parsed.Path = enterpriseRESTPath
+	}
+	return parsed.String(), nil
+}
+
+// ValidateCABundle checks the CA bundle holds at least one PEM encoded certificate.
+func ValidateCABundle(caBundle []byte) error {
+	if !x509.NewCertPool().AppendCertsFromPEM(caBundle) {
+		return fmt.Errorf("%w: the CA bundle holds no PEM encoded certificate", ErrInvalidEndpoint)
+	}
+	return nil
+}
+
+// graphqlURL returns the url of the GraphQL API, which GitHub Enterprise Server serves next to the REST API and not under it.
+func (e endpoint) graphqlURL() string {
+	if strings.HasSuffix(e.baseURL, enterpriseRESTPath) {
+		return strings.TrimSuffix(e.baseURL, enterpriseRESTPath) + enterpriseGraphQLPath
+	}
+	return e.baseURL + graphqlPath
+}
+
+// key returns a key identifying the endpoint.
+func (e endpoint) key() string {
+	return fmt.Sprintf("%s|%x", e.baseURL, sha256.Sum256(e.caBundle))
+}
+
+// endpointTransport is the transport trusting the CA bundle of an endpoint, kept to reuse its connections.
+type endpointTransport struct {
+	caBundleHash [sha256.Size]byte
+	transport    *http.Transport
+}
+
+// httpClient returns the http client used to talk to the endpoint, trusting its CA bundle on top of the system CAs.
+// The transport of an endpoint is shared between reconciles, and replaced when its CA bundle changes.
+func (e endpoint) httpClient() (*http.Client, error) {
+	if len(e.caBundle) == 0 {
+		return &http.Client{Timeout: requestTimeout}, nil
+	}
+
+	transportsMutex.Lock()
+	defer transportsMutex.Unlock()
+
+	caBundleHash := sha256.Sum256(e.caBundle)
+	if cached, ok := transports[e.baseURL]; ok {
+		if cached.caBundleHash == caBundleHash {
+			return &http.Client{Timeout: requestTimeout, Transport: cached.transport}, nil
+		}
+		cached.transport.CloseIdleConnections()
+		delete(transports, e.baseURL)
+	}
+
+	pool, err := x509.SystemCertPool()
+	if err != nil {
+		pool = x509.NewCertPool()
+	}
+	if !pool.AppendCertsFromPEM(e.caBundle) {
+		return nil, fmt.Errorf("%w: the CA bundle holds no PEM encoded certificate", ErrInvalidEndpoint)
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = &tls.Config{
+		RootCAs:    pool,
+		MinVersion: tls.VersionTLS12,
+	}
+	transports[e.baseURL] = &endpointTransport{caBundleHash: caBundleHash, transport: transport}
+	return &http.Client{Timeout: requestTimeout, Transport: transport}, nil
+}
@@ -0,0 +1,107 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package git
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+// testCertificate is the self-signed certificate returned by testCABundle.
+var testCertificate []byte
+
+// testCABundle returns a PEM encoded self-signed CA certificate.
+func testCABundle() []byte {
+	if testCertificate != nil {
+		return testCertificate
+	}
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	Expect(err).NotTo(HaveOccurred())
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "github.example.com"},
+		NotBefore:             time.Now(),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	Expect(err).NotTo(HaveOccurred())
+	testCertificate = pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+	return testCertificate
+}
+
+var _ = Describe("GitHub API endpoint", func() {
+	It("should keep the public API url as is", func() {
+		baseURL, err := ValidateBaseURL("https://api.github.com/")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(baseURL).To(Equal("https://api.github.com"))
+		Expect(endpoint{baseURL: baseURL}.graphqlURL()).To(Equal("https://api.github.com/graphql"))
+	})
+
+	It("should add the /api/v3 prefix to GitHub Enterprise Server urls", func() {
+		baseURL, err := ValidateBaseURL("https://github.example.com")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(baseURL).To(Equal("https://github.example.com/api/v3"))
+		Expect(endpoint{baseURL: baseURL}.graphqlURL()).To(Equal("https://github.example.com/api/graphql"))
+	})
+
+	It("should reject urls the url templates can't be used with", func() {
+		for _, raw := range []string{"github.example.com", "ftp://github.example.com", "https://github.example.com/api/v3?x=1"} {
+			_, err := ValidateBaseURL(raw)
+			Expect(err).To(MatchError(ErrInvalidEndpoint))
+		}
+	})
+
+	It("should reject http urls unless insecure http is allowed", func() {
+		_, err := ValidateBaseURL("http://github.example.com")
+		Expect(err).To(MatchError(ContainSubstring("plaintext")))
+
+		AllowInsecureHTTP = true
+		DeferCleanup(func() { AllowInsecureHTTP = false })
+		baseURL, err := ValidateBaseURL("http://github.example.com")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(baseURL).To(Equal("http://github.example.com/api/v3"))
+	})
+
+	It("should reuse the transport of an endpoint until its CA bundle changes", func() {
+		first, err := endpoint{baseURL: "https://github.example.com/api/v3", caBundle: testCABundle()}.httpClient()
+		Expect(err).NotTo(HaveOccurred())
+		second, err := endpoint{baseURL: "https://github.example.com/api/v3", caBundle: testCABundle()}.httpClient()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(second.Transport).To(BeIdenticalTo(first.Transport))
+
+		rotated, err := endpoint{baseURL: "https://github.example.com/api/v3", caBundle: bytes.Repeat(testCABundle(), 2)}.httpClient()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(rotated.Transport).NotTo(BeIdenticalTo(first.Transport))
+		Expect(transports).To(HaveLen(1))
+	})
+
+	It("should reject a CA bundle without certificates", func() {
+		Expect(ValidateCABundle([]byte("not a certificate"))).To(MatchError(ErrInvalidEndpoint))
+	})
+})
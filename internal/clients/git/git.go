@@ -2,14 +2,20 @@ package git
 
 import (
 	"context"
+	"crypto/sha256"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"net/http"
+	neturl "net/url"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/go-logr/logr"
 	"golang.org/x/oauth2"
 	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	maromdanaiov1alpha1 "my.domain/githubissue/api/v1alpha1"
 	httpClient "my.domain/githubissue/internal/clients/http"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -17,99 +23,389 @@ import (
 
 var (
 	APIBaseURL    = "https://api.github.com"
-	open          = "open"
 	closed        = "closed"
+	all           = "all"
+	perPage       = 100
+	link          = "Link"
 	url           = "%s/repos/%s/%s/issues"
 	urlWithNumber = "%s/repos/%s/%s/issues/%d"
+	repoUrl       = "%s/repos/%s/%s"
 	secretName    = "github-token"
 	secretKey     = "token"
 	namespace     = "github-operator-system"
+
+	timelineUrl        = "%s/repos/%s/%s/issues/%d/timeline?per_page=%d"
+	commentUrl         = "%s/repos/%s/%s/issues/comments/%d"
+	commentsUrl        = "%s/repos/%s/%s/issues/%d/comments?per_page=%d"
+	labelsUrl          = "%s/repos/%s/%s/labels?per_page=%d"
+	labelUrl           = "%s/repos/%s/%s/labels/%s"
+	createLabelUrl     = "%s/repos/%s/%s/labels"
+	milestonesUrl      = "%s/repos/%s/%s/milestones?state=all&per_page=%d"
+	milestoneUrl       = "%s/repos/%s/%s/milestones/%d"
+	createMilestoneUrl = "%s/repos/%s/%s/milestones"
+	crossReferenced    = "cross-referenced"
 )
 
 type GitClient interface {
-	GetRepositoryIssues(owner string, repo string, logger logr.Logger) ([]maromdanaiov1alpha1.IssueResponse, error)
-	CreateIssue(ctx context.Context, owner string, repo string, title string, body string, logger logr.Logger) (*maromdanaiov1alpha1.IssueResponse, error)
-	UpdateIssue(ctx context.Context, owner string, repo string, number int, body string, title string, logger logr.Logger) (*maromdanaiov1alpha1.IssueResponse, error)
-	CloseIssue(ctx context.Context, owner string, repo string, githubIssue *maromdanaiov1alpha1.GitHubIssue, logger logr.Logger) error
+	GetRepositoryIssues(owner string, repo string, opts IssueListOptions, logger logr.Logger) ([]maromdanaiov1alpha1.IssueResponse, error)
+	GetIssue(ctx context.Context, owner string, repo string, number int, logger logr.Logger) (*maromdanaiov1alpha1.IssueResponse, error)
+	CreateIssue(ctx context.Context, owner string, repo string, issue *maromdanaiov1alpha1.IssueRequest, logger logr.Logger) (*maromdanaiov1alpha1.IssueResponse, error)
+	UpdateIssue(ctx context.Context, owner string, repo string, number int, issue *maromdanaiov1alpha1.IssueRequest, logger logr.Logger) (*maromdanaiov1alpha1.IssueResponse, error)
+	CloseIssue(ctx context.Context, owner string, repo string, githubIssue *maromdanaiov1alpha1.GitHubIssue, stateReason string, logger logr.Logger) error
+	LockIssue(ctx context.Context, owner string, repo string, number int, lockReason string, logger logr.Logger) error
+	TransferIssue(ctx context.Context, nodeID string, targetOwner string, targetRepo string, logger logr.Logger) error
+	ListComments(ctx context.Context, owner string, repo string, number int, logger logr.Logger) ([]maromdanaiov1alpha1.CommentResponse, error)
+	CreateComment(ctx context.Context, owner string, repo string, number int, body string, logger logr.Logger) (*maromdanaiov1alpha1.CommentResponse, error)
+	GetComment(ctx context.Context, owner string, repo string, id int64, logger logr.Logger) (*maromdanaiov1alpha1.CommentResponse, error)
+	UpdateComment(ctx context.Context, owner string, repo string, id int64, body string, logger logr.Logger) (*maromdanaiov1alpha1.CommentResponse, error)
+	DeleteComment(ctx context.Context, owner string, repo string, id int64, logger logr.Logger) error
+	ListLabels(ctx context.Context, owner string, repo string, logger logr.Logger) ([]maromdanaiov1alpha1.Label, error)
+	CreateLabel(ctx context.Context, owner string, repo string, label *maromdanaiov1alpha1.LabelRequest, logger logr.Logger) error
+	UpdateLabel(ctx context.Context, owner string, repo string, name string, label *maromdanaiov1alpha1.LabelRequest, logger logr.Logger) error
+	DeleteLabel(ctx context.Context, owner string, repo string, name string, logger logr.Logger) error
+	ListMilestones(ctx context.Context, owner string, repo string, logger logr.Logger) ([]maromdanaiov1alpha1.Milestone, error)
+	GetMilestone(ctx context.Context, owner string, repo string, number int, logger logr.Logger) (*maromdanaiov1alpha1.Milestone, error)
+	CreateMilestone(ctx context.Context, owner string, repo string, milestone *maromdanaiov1alpha1.MilestoneRequest, logger logr.Logger) (*maromdanaiov1alpha1.Milestone, error)
+	UpdateMilestone(ctx context.Context, owner string, repo string, number int, milestone *maromdanaiov1alpha1.MilestoneRequest, logger logr.Logger) (*maromdanaiov1alpha1.Milestone, error)
+	GetLinkedPullRequests(ctx context.Context, owner string, repo string, number int, logger logr.Logger) ([]maromdanaiov1alpha1.LinkedPullRequest, error)
 	FindIssue(issues []maromdanaiov1alpha1.IssueResponse, title string) *maromdanaiov1alpha1.IssueResponse
 }
 
+// IssueListOptions filters the issues listed by GetRepositoryIssues.
+type IssueListOptions struct {
+	// Labels only lists issues that have all the given labels
+	Labels []string
+	// Creator only lists issues created by the given user
+	Creator string
+	// Since only lists issues updated at or after the given time
+	Since time.Time
+	// StopAt stops the listing once an issue it returns true for is found
+	StopAt func(issue maromdanaiov1alpha1.IssueResponse) bool
+}
+
+// query returns the query parameters sent to GitHub for the list options.
+func (o IssueListOptions) query() neturl.Values {
+	query := neturl.Values{}
+	query.Set("state", all)
+	query.Set("per_page", strconv.Itoa(perPage))
+	if len(o.Labels) > 0 {
+		query.Set("labels", strings.Join(o.Labels, ","))
+	}
+	if o.Creator != "" {
+		query.Set("creator", o.Creator)
+	}
+	if !o.Since.IsZero() {
+		query.Set("since", o.Since.UTC().Format(time.RFC3339))
+	}
+	return query
+}
+
+// closeRequest defines the structure for the request closing an issue
+type closeRequest struct {
+	State       string `json:"state"`
+	StateReason string `json:"state_reason,omitempty"`
+}
+
+// lockRequest defines the structure for the lock request sent
+type lockRequest struct {
+	LockReason string `json:"lock_reason,omitempty"`
+}
+
+// timelineEvent defines the structure for an issue timeline event given back
+type timelineEvent struct {
+	Event  string `json:"event"`
+	Source *struct {
+		Issue *timelineIssue `json:"issue"`
+	} `json:"source,omitempty"`
+}
+
+// timelineIssue defines the structure for the issue or pull request a cross-referenced timeline event comes from
+type timelineIssue struct {
+	Number           int                                   `json:"number"`
+	HTMLURL          string                                `json:"html_url"`
+	State            string                                `json:"state"`
+	PullRequestLinks *maromdanaiov1alpha1.PullRequestLinks `json:"pull_request,omitempty"`
+	Repository       struct {
+		FullName string `json:"full_name"`
+	} `json:"repository"`
+}
+
+// repositoryResponse defines the structure for the repository given back
+type repositoryResponse struct {
+	NodeID string `json:"node_id"`
+}
+
+// graphqlRequest defines the structure for a GraphQL request sent
+type graphqlRequest struct {
+	Query     string            `json:"query"`
+	Variables map[string]string `json:"variables"`
+}
+
+// graphqlResponseBody defines the structure for the errors of a GraphQL response given back
+type graphqlResponseBody struct {
+	Errors []struct {
+		Message string `json:"message"`
+	} `json:"errors,omitempty"`
+}
+
+const transferMutation = `mutation($issueId: ID!, $repositoryId: ID!) {
+  transferIssue(input: {issueId: $issueId, repositoryId: $repositoryId}) {
+    issue {
+      number
+    }
+  }
+}`
+
+var (
+	// ErrSecretNotFound is returned when the secret holding the GitHub token does not exist
+	ErrSecretNotFound = errors.New("GitHub token secret not found")
+	// ErrSecretKeyNotFound is returned when the secret holding the GitHub token has no token key
+	ErrSecretKeyNotFound = errors.New("GitHub token not found in secret")
+)
+
 type GitClientInitializer interface {
-	InitializeGit(ctx context.Context) (*http.Client, error)
+	InitializeGit(ctx context.Context, namespace string, credentialsRef *maromdanaiov1alpha1.CredentialsRef) (GitClient, error)
 }
 
 type GitHubClientInitializer struct {
-	HttpClient client.Client
+	HttpClient client.Reader
 }
 
 // InitializeGit initialized an authorized client
-func (g *GitHubClientInitializer) InitializeGit(ctx context.Context) (GitClient, error) {
+// The secret may point the client to a GitHub Enterprise Server with the apiUrl and caBundle keys.
+// GitHub App credentials are exchanged for installation tokens, other tokens are read from the referenced secret in the given namespace, or from the operator wide github-token secret when no secret is referenced.
+func (g *GitHubClientInitializer) InitializeGit(ctx context.Context, namespace string, credentialsRef *maromdanaiov1alpha1.CredentialsRef) (GitClient, error) {
+	key := credentialsSecret(namespace, credentialsRef)
+	tokenKey := secretKey
+	if credentialsRef != nil && credentialsRef.Key != "" {
+		tokenKey = credentialsRef.Key
+	}
+
 	secret := &corev1.Secret{}
-	err := g.HttpClient.Get(ctx, client.ObjectKey{Namespace: namespace, Name: secretName}, secret)
+	err := g.HttpClient.Get(ctx, key, secret)
 	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil, fmt.Errorf("%w: %s", ErrSecretNotFound, key)
+		}
 		return nil, errors.New("unable to read GitHub token secret: " + err.Error())
 	}
 
-	token, ok := secret.Data[secretKey]
-	if !ok {
-		return nil, errors.New("GitHub token not found in secret")
+	githubEndpoint, err := endpointFromSecret(secret.Data)
+	if err != nil {
+		return nil, fmt.Errorf("%w in %s", err, key)
+	}
+	endpointClient, err := githubEndpoint.httpClient()
+	if err != nil {
+		return nil, fmt.Errorf("%w in %s", err, key)
 	}
 
-	sourceToken := oauth2.StaticTokenSource(
-		&oauth2.Token{AccessToken: string(token)},
-	)
-	oauth2Client := oauth2.NewClient(ctx, sourceToken)
+	var sourceToken oauth2.TokenSource
+	var rateLimitKey string
+	if credentialsRef != nil && credentialsRef.Type == maromdanaiov1alpha1.CredentialsTypeGitHubApp {
+		credentials, err := appCredentialsFromSecret(secret.Data)
+		if err != nil {
+			return nil, fmt.Errorf("%w in %s", err, key)
+		}
+		sourceToken, err = newAppTokenSource(githubEndpoint, credentials)
+		if err != nil {
+			return nil, err
+		}
+		rateLimitKey = fmt.Sprintf("app:%s/%s", credentials.AppID, credentials.InstallationID)
+	} else {
+		token, ok := secret.Data[tokenKey]
+		if !ok {
+			return nil, fmt.Errorf("%w: key %q in %s", ErrSecretKeyNotFound, tokenKey, key)
+		}
+
+		sourceToken = oauth2.StaticTokenSource(
+			&oauth2.Token{AccessToken: string(token)},
+		)
+		rateLimitKey = tokenRateLimitKey(token)
+	}
+	oauth2Client := oauth2.NewClient(context.WithValue(ctx, oauth2.HTTPClient, endpointClient), sourceToken)
 	HttpClient := &httpClient.HttpClient{
-		Client: oauth2Client,
+		Client:   oauth2Client,
+		TokenKey: rateLimitKey,
 	}
 
-	return &GitHubClient{HttpClient: HttpClient}, nil
+	return &GitHubClient{HttpClient: HttpClient, endpoint: githubEndpoint}, nil
+}
+
+// tokenRateLimitKey returns the key the rate limit of the token is tracked by, without exposing the token itself.
+func tokenRateLimitKey(token []byte) string {
+	return fmt.Sprintf("token:%x", sha256.Sum256(token))[:len("token:")+12]
+}
+
+// credentialsSecret returns the key of the secret holding the GitHub token.
+// Referenced secrets are always read from the namespace of the GitHubIssue, so a GitHubIssue can't use another namespace's token.
+func credentialsSecret(issueNamespace string, credentialsRef *maromdanaiov1alpha1.CredentialsRef) client.ObjectKey {
+	if credentialsRef == nil {
+		return client.ObjectKey{Namespace: namespace, Name: secretName}
+	}
+	return client.ObjectKey{Namespace: issueNamespace, Name: credentialsRef.Name}
 }
 
 type GitHubClient struct {
 	HttpClient *httpClient.HttpClient
+	endpoint   endpoint
 }
 
-// GetRepositoryIssues gets all the issues listed in the given repository.
-func (r *GitHubClient) GetRepositoryIssues(owner string, repo string, logger logr.Logger) ([]maromdanaiov1alpha1.IssueResponse, error) {
-	url := fmt.Sprintf(url, APIBaseURL, owner, repo)
+// GetRepositoryIssues gets all the issues listed in the given repository matching the list options.
+// It follows the pagination links until the last page, or until an issue matching opts.StopAt is found.
+func (r *GitHubClient) GetRepositoryIssues(owner string, repo string, opts IssueListOptions, logger logr.Logger) ([]maromdanaiov1alpha1.IssueResponse, error) {
+	url := r.createUrl(owner, repo) + "?" + opts.query().Encode()
+
+	var issues []maromdanaiov1alpha1.IssueResponse
+	for url != "" {
+		page, next, err := r.getIssuesPage(url, logger)
+		if err != nil {
+			return nil, err
+		}
 
-	issue := maromdanaiov1alpha1.IssueRequest{}
+		for _, issue := range page {
+			issues = append(issues, issue)
+			if opts.StopAt != nil && opts.StopAt(issue) {
+				return issues, nil
+			}
+		}
+		url = next
+	}
 
-	response, err := r.HttpClient.SendRequest(url, http.MethodGet, issue)
+	return issues, nil
+}
+
+// getIssuesPage gets a single page of issues and returns it with the url of the next page, if there is one.
+func (r *GitHubClient) getIssuesPage(url string, logger logr.Logger) ([]maromdanaiov1alpha1.IssueResponse, string, error) {
+	response, err := r.HttpClient.SendRequest(url, http.MethodGet, nil)
 	if err != nil {
 		logger.Error(err, "failed to list all github issues")
-		return nil, err
+		return nil, "", err
 	}
+	defer response.Body.Close()
 
 	if response.StatusCode != http.StatusOK {
 		err := fmt.Errorf("failed to list all github issues with status code: %d", response.StatusCode)
 		logger.Error(err, "")
-		return nil, err
+		return nil, "", err
 	}
 
 	var issues []maromdanaiov1alpha1.IssueResponse
 	if err := json.NewDecoder(response.Body).Decode(&issues); err != nil {
+		return nil, "", err
+	}
+
+	return issues, nextPageUrl(response.Header.Get(link)), nil
+}
+
+// GetIssue gets the issue with the given number, it returns nil if the issue no longer exists.
+func (r *GitHubClient) GetIssue(ctx context.Context, owner string, repo string, number int, logger logr.Logger) (*maromdanaiov1alpha1.IssueResponse, error) {
+	url := r.createUrlWithIssueNumber(owner, repo, number)
+
+	response, err := r.HttpClient.SendRequest(url, http.MethodGet, nil)
+	if err != nil {
+		logger.Error(err, "failed to get github issue", "number", number)
 		return nil, err
 	}
+	defer response.Body.Close()
 
-	return issues, nil
+	if response.StatusCode == http.StatusNotFound || response.StatusCode == http.StatusGone {
+		return nil, nil
+	}
+
+	if response.StatusCode != http.StatusOK {
+		err := fmt.Errorf("failed to get github issue %d with status code: %d", number, response.StatusCode)
+		logger.Error(err, "")
+		return nil, err
+	}
+
+	var result maromdanaiov1alpha1.IssueResponse
+	if err := json.NewDecoder(response.Body).Decode(&result); err != nil {
+		return nil, err
+	}
+
+	return &result, nil
 }
 
 // CreateIssue creates an issue.
-func (r *GitHubClient) CreateIssue(ctx context.Context, owner string, repo string, title string, body string, logger logr.Logger) (*maromdanaiov1alpha1.IssueResponse, error) {
-	url := createUrl(owner, repo)
+func (r *GitHubClient) CreateIssue(ctx context.Context, owner string, repo string, issue *maromdanaiov1alpha1.IssueRequest, logger logr.Logger) (*maromdanaiov1alpha1.IssueResponse, error) {
+	url := r.createUrl(owner, repo)
 
-	issue := maromdanaiov1alpha1.IssueRequest{
-		Title: title,
-		Body:  body,
-		State: "open",
+	response, err := r.HttpClient.SendRequest(url, http.MethodPost, issue)
+
+	if err != nil {
+		logger.Error(err, "Failed to send request")
+		return nil, err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusCreated {
+		err := fmt.Errorf("failed to create github issue with status code: %d", response.StatusCode)
+		logger.Error(err, "")
+		return nil, err
+	}
+
+	var result maromdanaiov1alpha1.IssueResponse
+	if err = json.NewDecoder(response.Body).Decode(&result); err != nil {
+		return nil, err
+	}
+
+	return &result, nil
+}
+
+// CloseIssue changes the issue status to "closed" with the given state reason.
+// The issue is addressed by the number recorded in the status, title matching is only used when no number was recorded yet.
+func (r *GitHubClient) CloseIssue(ctx context.Context, owner string, repo string, githubIssue *maromdanaiov1alpha1.GitHubIssue, stateReason string, logger logr.Logger) error {
+	number := githubIssue.Status.Number
+	if number == 0 {
+		issues, err := r.GetRepositoryIssues(owner, repo, IssueListOptions{StopAt: MatchTitle(githubIssue.Spec.Title)}, logger)
+		if err != nil {
+			return err
+		}
+
+		foundIssue := r.FindIssue(issues, githubIssue.Spec.Title)
+
+		if foundIssue == nil {
+			return fmt.Errorf("issue not found")
+		}
+		number = foundIssue.Number
+	}
+
+	url := r.createUrlWithIssueNumber(owner, repo, number)
+	// Only the state is sent, so the title and the body with its ownership marker are left as they are.
+	issue := &closeRequest{
+		State:       closed,
+		StateReason: stateReason,
 	}
 
 	response, err := r.HttpClient.SendRequest(url, http.MethodPost, issue)
+	if err != nil {
+		logger.Error(err, "Failed to send request")
+		return err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		err := fmt.Errorf("failed to close github issue %d with status code: %d", number, response.StatusCode)
+		logger.Error(err, "")
+		return err
+	}
+	return nil
+}
+
+// UpdateIssue updates the issue with the fields set in the issue request.
+func (r *GitHubClient) UpdateIssue(ctx context.Context, owner string, repo string, number int, issue *maromdanaiov1alpha1.IssueRequest, logger logr.Logger) (*maromdanaiov1alpha1.IssueResponse, error) {
+	url := r.createUrlWithIssueNumber(owner, repo, number)
 
+	response, err := r.HttpClient.SendRequest(url, http.MethodPost, issue)
 	if err != nil {
-		logger.Error(err, "Failed to send request with status code", response.StatusCode)
+		logger.Error(err, "Failed to send request")
+		return nil, err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		err := fmt.Errorf("failed to update github issue %d with status code: %d", number, response.StatusCode)
+		logger.Error(err, "")
 		return nil, err
 	}
 
@@ -121,72 +417,509 @@ func (r *GitHubClient) CreateIssue(ctx context.Context, owner string, repo strin
 	return &result, nil
 }
 
-// CloseIssue changes the issue status to "closed".
-func (r *GitHubClient) CloseIssue(ctx context.Context, owner string, repo string, githubIssue *maromdanaiov1alpha1.GitHubIssue, logger logr.Logger) error {
-	issues, err := r.GetRepositoryIssues(owner, repo, logger)
+// LockIssue locks the conversation of the issue with the given lock reason.
+func (r *GitHubClient) LockIssue(ctx context.Context, owner string, repo string, number int, lockReason string, logger logr.Logger) error {
+	url := r.createUrlWithIssueNumber(owner, repo, number) + "/lock"
+
+	response, err := r.HttpClient.SendRequest(url, http.MethodPut, &lockRequest{LockReason: lockReason})
 	if err != nil {
+		logger.Error(err, "Failed to send request")
 		return err
 	}
+	defer response.Body.Close()
 
-	foundIssue := r.FindIssue(issues, githubIssue.Spec.Title)
+	if response.StatusCode != http.StatusNoContent {
+		err := fmt.Errorf("failed to lock github issue %d with status code: %d", number, response.StatusCode)
+		logger.Error(err, "")
+		return err
+	}
+	return nil
+}
 
-	if foundIssue == nil {
-		return fmt.Errorf("issue not found")
+// TransferIssue transfers the issue with the given node id to the target repository.
+// GitHub only exposes issue transfers through its GraphQL API, so the transfer is sent as a transferIssue mutation.
+func (r *GitHubClient) TransferIssue(ctx context.Context, nodeID string, targetOwner string, targetRepo string, logger logr.Logger) error {
+	response, err := r.HttpClient.SendRequest(fmt.Sprintf(repoUrl, r.baseURL(), targetOwner, targetRepo), http.MethodGet, nil)
+	if err != nil {
+		logger.Error(err, "Failed to send request")
+		return err
 	}
+	defer response.Body.Close()
 
-	url := createUrlWithIssueNumber(owner, repo, foundIssue.Number)
-	issue := &maromdanaiov1alpha1.IssueRequest{
-		Title: githubIssue.Spec.Title,
-		State: closed,
-		Body:  githubIssue.Spec.Description,
+	if response.StatusCode != http.StatusOK {
+		err := fmt.Errorf("failed to get github repository %s/%s with status code: %d", targetOwner, targetRepo, response.StatusCode)
+		logger.Error(err, "")
+		return err
 	}
 
-	response, err := r.HttpClient.SendRequest(url, http.MethodPost, issue)
+	var repository repositoryResponse
+	if err := json.NewDecoder(response.Body).Decode(&repository); err != nil {
+		return err
+	}
 
-	var result maromdanaiov1alpha1.IssueResponse
-	if err = json.NewDecoder(response.Body).Decode(&result); err != nil {
+	mutation := &graphqlRequest{
+		Query: transferMutation,
+		Variables: map[string]string{
+			"issueId":      nodeID,
+			"repositoryId": repository.NodeID,
+		},
+	}
+	graphqlResponse, err := r.HttpClient.SendRequest(r.graphqlURL(), http.MethodPost, mutation)
+	if err != nil {
 		logger.Error(err, "Failed to send request")
 		return err
 	}
+	defer graphqlResponse.Body.Close()
+
+	var result graphqlResponseBody
+	if err := json.NewDecoder(graphqlResponse.Body).Decode(&result); err != nil {
+		return err
+	}
+	if graphqlResponse.StatusCode != http.StatusOK || len(result.Errors) > 0 {
+		err := fmt.Errorf("failed to transfer github issue to %s/%s with status code: %d: %v", targetOwner, targetRepo, graphqlResponse.StatusCode, result.Errors)
+		logger.Error(err, "")
+		return err
+	}
 	return nil
 }
 
-// UpdateIssue updates the issue description.
-func (r *GitHubClient) UpdateIssue(ctx context.Context, owner string, repo string, number int, body string, title string, logger logr.Logger) (*maromdanaiov1alpha1.IssueResponse, error) {
-	url := createUrlWithIssueNumber(owner, repo, number)
+// ListComments lists the comments of the issue.
+func (r *GitHubClient) ListComments(ctx context.Context, owner string, repo string, number int, logger logr.Logger) ([]maromdanaiov1alpha1.CommentResponse, error) {
+	var comments []maromdanaiov1alpha1.CommentResponse
+
+	url := fmt.Sprintf(commentsUrl, r.baseURL(), owner, repo, number, perPage)
+	for url != "" {
+		response, err := r.HttpClient.SendRequest(url, http.MethodGet, nil)
+		if err != nil {
+			logger.Error(err, "failed to list github comments", "number", number)
+			return nil, err
+		}
+
+		if response.StatusCode != http.StatusOK {
+			response.Body.Close()
+			err := fmt.Errorf("failed to list comments of github issue %d with status code: %d", number, response.StatusCode)
+			logger.Error(err, "")
+			return nil, err
+		}
 
-	issue := &maromdanaiov1alpha1.IssueRequest{
-		Title: title,
-		Body:  body,
-		State: open,
+		var page []maromdanaiov1alpha1.CommentResponse
+		err = json.NewDecoder(response.Body).Decode(&page)
+		response.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		comments = append(comments, page...)
+		url = nextPageUrl(response.Header.Get(link))
 	}
 
-	response, err := r.HttpClient.SendRequest(url, http.MethodPost, issue)
+	return comments, nil
+}
 
-	var result maromdanaiov1alpha1.IssueResponse
-	if err = json.NewDecoder(response.Body).Decode(&result); err != nil {
+// CreateComment adds a comment with the given body to the issue.
+func (r *GitHubClient) CreateComment(ctx context.Context, owner string, repo string, number int, body string, logger logr.Logger) (*maromdanaiov1alpha1.CommentResponse, error) {
+	url := r.createUrlWithIssueNumber(owner, repo, number) + "/comments"
+
+	response, err := r.HttpClient.SendRequest(url, http.MethodPost, &maromdanaiov1alpha1.CommentRequest{Body: body})
+	if err != nil {
+		logger.Error(err, "Failed to send request")
 		return nil, err
 	}
+	defer response.Body.Close()
 
+	if response.StatusCode != http.StatusCreated {
+		err := fmt.Errorf("failed to comment on github issue %d with status code: %d", number, response.StatusCode)
+		logger.Error(err, "")
+		return nil, err
+	}
+
+	var result maromdanaiov1alpha1.CommentResponse
+	if err := json.NewDecoder(response.Body).Decode(&result); err != nil {
+		return nil, err
+	}
 	return &result, nil
 }
 
-// FindIssue finds the issue in the lissues list with the same title as the one in thr githubIssue.
+// GetComment gets the comment with the given id, it returns nil if the comment no longer exists.
+func (r *GitHubClient) GetComment(ctx context.Context, owner string, repo string, id int64, logger logr.Logger) (*maromdanaiov1alpha1.CommentResponse, error) {
+	url := fmt.Sprintf(commentUrl, r.baseURL(), owner, repo, id)
+
+	response, err := r.HttpClient.SendRequest(url, http.MethodGet, nil)
+	if err != nil {
+		logger.Error(err, "failed to get github comment", "id", id)
+		return nil, err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode == http.StatusNotFound || response.StatusCode == http.StatusGone {
+		return nil, nil
+	}
+
+	if response.StatusCode != http.StatusOK {
+		err := fmt.Errorf("failed to get github comment %d with status code: %d", id, response.StatusCode)
+		logger.Error(err, "")
+		return nil, err
+	}
+
+	var result maromdanaiov1alpha1.CommentResponse
+	if err := json.NewDecoder(response.Body).Decode(&result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// UpdateComment replaces the body of the comment with the given id.
+func (r *GitHubClient) UpdateComment(ctx context.Context, owner string, repo string, id int64, body string, logger logr.Logger) (*maromdanaiov1alpha1.CommentResponse, error) {
+	url := fmt.Sprintf(commentUrl, r.baseURL(), owner, repo, id)
+
+	response, err := r.HttpClient.SendRequest(url, http.MethodPatch, &maromdanaiov1alpha1.CommentRequest{Body: body})
+	if err != nil {
+		logger.Error(err, "Failed to send request")
+		return nil, err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		err := fmt.Errorf("failed to update github comment %d with status code: %d", id, response.StatusCode)
+		logger.Error(err, "")
+		return nil, err
+	}
+
+	var result maromdanaiov1alpha1.CommentResponse
+	if err := json.NewDecoder(response.Body).Decode(&result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// DeleteComment deletes the comment with the given id, a comment which no longer exists is not an error.
+func (r *GitHubClient) DeleteComment(ctx context.Context, owner string, repo string, id int64, logger logr.Logger) error {
+	url := fmt.Sprintf(commentUrl, r.baseURL(), owner, repo, id)
+
+	response, err := r.HttpClient.SendRequest(url, http.MethodDelete, nil)
+	if err != nil {
+		logger.Error(err, "Failed to send request")
+		return err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusNoContent && response.StatusCode != http.StatusNotFound {
+		err := fmt.Errorf("failed to delete github comment %d with status code: %d", id, response.StatusCode)
+		logger.Error(err, "")
+		return err
+	}
+	return nil
+}
+
+// ListLabels returns all the labels of the repo.
+func (r *GitHubClient) ListLabels(ctx context.Context, owner string, repo string, logger logr.Logger) ([]maromdanaiov1alpha1.Label, error) {
+	var labels []maromdanaiov1alpha1.Label
+
+	url := fmt.Sprintf(labelsUrl, r.baseURL(), owner, repo, perPage)
+	for url != "" {
+		response, err := r.HttpClient.SendRequest(url, http.MethodGet, nil)
+		if err != nil {
+			logger.Error(err, "failed to list github labels")
+			return nil, err
+		}
+
+		if response.StatusCode != http.StatusOK {
+			response.Body.Close()
+			err := fmt.Errorf("failed to list github labels with status code: %d", response.StatusCode)
+			logger.Error(err, "")
+			return nil, err
+		}
+
+		var page []maromdanaiov1alpha1.Label
+		err = json.NewDecoder(response.Body).Decode(&page)
+		response.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		labels = append(labels, page...)
+		url = nextPageUrl(response.Header.Get(link))
+	}
+
+	return labels, nil
+}
+
+// CreateLabel creates a label in the repo.
+func (r *GitHubClient) CreateLabel(ctx context.Context, owner string, repo string, label *maromdanaiov1alpha1.LabelRequest, logger logr.Logger) error {
+	url := fmt.Sprintf(createLabelUrl, r.baseURL(), owner, repo)
+
+	response, err := r.HttpClient.SendRequest(url, http.MethodPost, label)
+	if err != nil {
+		logger.Error(err, "Failed to send request")
+		return err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusCreated {
+		err := fmt.Errorf("failed to create github label %s with status code: %d", label.Name, response.StatusCode)
+		logger.Error(err, "")
+		return err
+	}
+	return nil
+}
+
+// UpdateLabel updates the label with the given name, setting NewName renames it.
+func (r *GitHubClient) UpdateLabel(ctx context.Context, owner string, repo string, name string, label *maromdanaiov1alpha1.LabelRequest, logger logr.Logger) error {
+	url := fmt.Sprintf(labelUrl, r.baseURL(), owner, repo, neturl.PathEscape(name))
+
+	response, err := r.HttpClient.SendRequest(url, http.MethodPatch, label)
+	if err != nil {
+		logger.Error(err, "Failed to send request")
+		return err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		err := fmt.Errorf("failed to update github label %s with status code: %d", name, response.StatusCode)
+		logger.Error(err, "")
+		return err
+	}
+	return nil
+}
+
+// DeleteLabel deletes the label with the given name, a label which no longer exists is not an error.
+func (r *GitHubClient) DeleteLabel(ctx context.Context, owner string, repo string, name string, logger logr.Logger) error {
+	url := fmt.Sprintf(labelUrl, r.baseURL(), owner, repo, neturl.PathEscape(name))
+
+	response, err := r.HttpClient.SendRequest(url, http.MethodDelete, nil)
+	if err != nil {
+		logger.Error(err, "Failed to send request")
+		return err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusNoContent && response.StatusCode != http.StatusNotFound {
+		err := fmt.Errorf("failed to delete github label %s with status code: %d", name, response.StatusCode)
+		logger.Error(err, "")
+		return err
+	}
+	return nil
+}
+
+// ListMilestones returns all the milestones of the repo, open and closed.
+func (r *GitHubClient) ListMilestones(ctx context.Context, owner string, repo string, logger logr.Logger) ([]maromdanaiov1alpha1.Milestone, error) {
+	var milestones []maromdanaiov1alpha1.Milestone
+
+	url := fmt.Sprintf(milestonesUrl, r.baseURL(), owner, repo, perPage)
+	for url != "" {
+		response, err := r.HttpClient.SendRequest(url, http.MethodGet, nil)
+		if err != nil {
+			logger.Error(err, "failed to list github milestones")
+			return nil, err
+		}
+
+		if response.StatusCode != http.StatusOK {
+			response.Body.Close()
+			err := fmt.Errorf("failed to list github milestones with status code: %d", response.StatusCode)
+			logger.Error(err, "")
+			return nil, err
+		}
+
+		var page []maromdanaiov1alpha1.Milestone
+		err = json.NewDecoder(response.Body).Decode(&page)
+		response.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		milestones = append(milestones, page...)
+		url = nextPageUrl(response.Header.Get(link))
+	}
+
+	return milestones, nil
+}
+
+// GetMilestone gets the milestone with the given number, it returns nil if the milestone no longer exists.
+func (r *GitHubClient) GetMilestone(ctx context.Context, owner string, repo string, number int, logger logr.Logger) (*maromdanaiov1alpha1.Milestone, error) {
+	url := fmt.Sprintf(milestoneUrl, r.baseURL(), owner, repo, number)
+
+	response, err := r.HttpClient.SendRequest(url, http.MethodGet, nil)
+	if err != nil {
+		logger.Error(err, "failed to get github milestone", "number", number)
+		return nil, err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode == http.StatusNotFound {
+		return nil, nil
+	}
+
+	if response.StatusCode != http.StatusOK {
+		err := fmt.Errorf("failed to get github milestone %d with status code: %d", number, response.StatusCode)
+		logger.Error(err, "")
+		return nil, err
+	}
+
+	var result maromdanaiov1alpha1.Milestone
+	if err := json.NewDecoder(response.Body).Decode(&result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// CreateMilestone creates a milestone in the repo.
+func (r *GitHubClient) CreateMilestone(ctx context.Context, owner string, repo string, milestone *maromdanaiov1alpha1.MilestoneRequest, logger logr.Logger) (*maromdanaiov1alpha1.Milestone, error) {
+	url := fmt.Sprintf(createMilestoneUrl, r.baseURL(), owner, repo)
+
+	response, err := r.HttpClient.SendRequest(url, http.MethodPost, milestone)
+	if err != nil {
+		logger.Error(err, "Failed to send request")
+		return nil, err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusCreated {
+		err := fmt.Errorf("failed to create github milestone %s with status code: %d", milestone.Title, response.StatusCode)
+		logger.Error(err, "")
+		return nil, err
+	}
+
+	var result maromdanaiov1alpha1.Milestone
+	if err := json.NewDecoder(response.Body).Decode(&result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// UpdateMilestone updates the milestone with the given number.
+func (r *GitHubClient) UpdateMilestone(ctx context.Context, owner string, repo string, number int, milestone *maromdanaiov1alpha1.MilestoneRequest, logger logr.Logger) (*maromdanaiov1alpha1.Milestone, error) {
+	url := fmt.Sprintf(milestoneUrl, r.baseURL(), owner, repo, number)
+
+	response, err := r.HttpClient.SendRequest(url, http.MethodPatch, milestone)
+	if err != nil {
+		logger.Error(err, "Failed to send request")
+		return nil, err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		err := fmt.Errorf("failed to update github milestone %d with status code: %d", number, response.StatusCode)
+		logger.Error(err, "")
+		return nil, err
+	}
+
+	var result maromdanaiov1alpha1.Milestone
+	if err := json.NewDecoder(response.Body).Decode(&result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// GetLinkedPullRequests returns the pull requests referencing the issue, found in the cross-referenced events of its timeline.
+func (r *GitHubClient) GetLinkedPullRequests(ctx context.Context, owner string, repo string, number int, logger logr.Logger) ([]maromdanaiov1alpha1.LinkedPullRequest, error) {
+	var pullRequests []maromdanaiov1alpha1.LinkedPullRequest
+	seen := map[string]bool{}
+
+	url := fmt.Sprintf(timelineUrl, r.baseURL(), owner, repo, number, perPage)
+	for url != "" {
+		events, next, err := r.getTimelinePage(url, number, logger)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, event := range events {
+			if event.Event != crossReferenced || event.Source == nil || event.Source.Issue == nil || event.Source.Issue.PullRequestLinks == nil {
+				continue
+			}
+			source := event.Source.Issue
+			key := fmt.Sprintf("%s#%d", strings.ToLower(source.Repository.FullName), source.Number)
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+			pullRequests = append(pullRequests, maromdanaiov1alpha1.LinkedPullRequest{
+				Repo:   source.Repository.FullName,
+				Number: source.Number,
+				URL:    source.HTMLURL,
+				State:  source.State,
+				Merged: source.PullRequestLinks.MergedAt != nil,
+			})
+		}
+		url = next
+	}
+
+	return pullRequests, nil
+}
+
+// getTimelinePage gets a single page of the issue timeline and returns it with the url of the next page, if there is one.
+func (r *GitHubClient) getTimelinePage(url string, number int, logger logr.Logger) ([]timelineEvent, string, error) {
+	response, err := r.HttpClient.SendRequest(url, http.MethodGet, nil)
+	if err != nil {
+		logger.Error(err, "failed to get github issue timeline", "number", number)
+		return nil, "", err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		err := fmt.Errorf("failed to get github issue %d timeline with status code: %d", number, response.StatusCode)
+		logger.Error(err, "")
+		return nil, "", err
+	}
+
+	var events []timelineEvent
+	if err := json.NewDecoder(response.Body).Decode(&events); err != nil {
+		return nil, "", err
+	}
+
+	return events, nextPageUrl(response.Header.Get(link)), nil
+}
+
+// FindIssue finds the issue in the issues list with the same title as the one in the githubIssue.
+// It is only used to adopt an existing issue before its number is recorded in the status.
+// GitHub lists pull requests as issues, they are skipped.
 func (r *GitHubClient) FindIssue(issues []maromdanaiov1alpha1.IssueResponse, title string) *maromdanaiov1alpha1.IssueResponse {
 	for _, issue := range issues {
-		if issue.Title == title {
+		if issue.Title == title && issue.PullRequestLinks == nil {
 			return &issue
 		}
 	}
 	return nil
 }
 
+// MatchTitle returns a StopAt function matching the issue with the given title.
+func MatchTitle(title string) func(issue maromdanaiov1alpha1.IssueResponse) bool {
+	return func(issue maromdanaiov1alpha1.IssueResponse) bool {
+		return issue.Title == title && issue.PullRequestLinks == nil
+	}
+}
+
+// nextPageUrl returns the url of the next page from the Link header, or an empty string on the last page.
+func nextPageUrl(header string) string {
+	for _, part := range strings.Split(header, ",") {
+		sections := strings.Split(part, ";")
+		if len(sections) < 2 {
+			continue
+		}
+		for _, param := range sections[1:] {
+			if strings.TrimSpace(param) == `rel="next"` {
+				return strings.Trim(strings.TrimSpace(sections[0]), "<>")
+			}
+		}
+	}
+	return ""
+}
+
+// baseURL returns the url of the GitHub API the client talks to.
+func (r *GitHubClient) baseURL() string {
+	if r.endpoint.baseURL == "" {
+		return APIBaseURL
+	}
+	return r.endpoint.baseURL
+}
+
+// graphqlURL returns the url of the GitHub GraphQL API the client talks to.
+func (r *GitHubClient) graphqlURL() string {
+	return endpoint{baseURL: r.baseURL()}.graphqlURL()
+}
+
 // createUrl returns the gitHub url we need to send / get the request to / from.
-func createUrl(owner string, repo string) string {
-	return fmt.Sprintf(url, APIBaseURL, owner, repo)
+func (r *GitHubClient) createUrl(owner string, repo string) string {
+	return fmt.Sprintf(url, r.baseURL(), owner, repo)
 }
 
 // createUrlWithIssueNumber returns the gitHub url we need to send / get the request to / from with a specific issue number.
-func createUrlWithIssueNumber(owner string, repo string, number int) string {
-	return fmt.Sprintf(urlWithNumber, APIBaseURL, owner, repo, number)
+func (r *GitHubClient) createUrlWithIssueNumber(owner string, repo string, number int) string {
+	return fmt.Sprintf(urlWithNumber, r.baseURL(), owner, repo, number)
 }
@@ -0,0 +1,337 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package git
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+
+	"github.com/go-logr/logr"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	maromdanaiov1alpha1 "my.domain/githubissue/api/v1alpha1"
+	httpClient "my.domain/githubissue/internal/clients/http"
+)
+
+var _ = Describe("Linked pull requests", func() {
+	var server *httptest.Server
+
+	BeforeEach(func() {
+		mux := http.NewServeMux()
+		mux.HandleFunc("/repos/owner/repo/issues/7/timeline", func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Query().Get("page") == "2" {
+				_, _ = fmt.Fprint(w, `[
+					{"event":"cross-referenced","source":{"type":"issue","issue":{"number":12,"html_url":"https://github.com/owner/repo/pull/12","state":"open","pull_request":{"url":"u","html_url":"h"},"repository":{"full_name":"owner/repo"}}}},
+					{"event":"cross-referenced","source":{"type":"issue","issue":{"number":15,"html_url":"https://github.com/owner/repo/pull/15","state":"closed","pull_request":{"url":"u","html_url":"h","merged_at":"2024-05-01T10:00:00Z"},"repository":{"full_name":"owner/repo"}}}}
+				]`)
+				return
+			}
+			w.Header().Set("Link", fmt.Sprintf(`<%s/repos/owner/repo/issues/7/timeline?page=2>; rel="next"`, server.URL))
+			_, _ = fmt.Fprint(w, `[
+				{"event":"labeled"},
+				{"event":"cross-referenced","source":{"type":"issue","issue":{"number":3,"html_url":"https://github.com/owner/repo/issues/3","state":"open","repository":{"full_name":"owner/repo"}}}},
+				{"event":"cross-referenced","source":{"type":"issue","issue":{"number":12,"html_url":"https://github.com/owner/repo/pull/12","state":"open","pull_request":{"url":"u","html_url":"h"},"repository":{"full_name":"owner/repo"}}}},
+				{"event":"cross-referenced","source":{"type":"issue","issue":{"number":4,"html_url":"https://github.com/owner/other/pull/4","state":"open","pull_request":{"url":"u","html_url":"h"},"repository":{"full_name":"owner/other"}}}}
+			]`)
+		})
+		server = httptest.NewServer(mux)
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	It("should return the pull requests cross-referencing the issue", func() {
+		client := &GitHubClient{
+			HttpClient: &httpClient.HttpClient{Client: server.Client(), TokenKey: "timeline-token"},
+			endpoint:   endpoint{baseURL: server.URL},
+		}
+
+		pullRequests, err := client.GetLinkedPullRequests(context.Background(), "owner", "repo", 7, logr.Discard())
+		Expect(err).NotTo(HaveOccurred())
+		Expect(pullRequests).To(Equal([]maromdanaiov1alpha1.LinkedPullRequest{
+			{Repo: "owner/repo", Number: 12, URL: "https://github.com/owner/repo/pull/12", State: "open"},
+			{Repo: "owner/other", Number: 4, URL: "https://github.com/owner/other/pull/4", State: "open"},
+			{Repo: "owner/repo", Number: 15, URL: "https://github.com/owner/repo/pull/15", State: "closed", Merged: true},
+		}))
+	})
+})
+
+var _ = Describe("Issues", func() {
+	var (
+		server *httptest.Server
+		client *GitHubClient
+		status int
+	)
+
+	BeforeEach(func() {
+		status = http.StatusOK
+		mux := http.NewServeMux()
+		mux.HandleFunc("/repos/owner/repo/issues", func(w http.ResponseWriter, r *http.Request) {
+			if status == http.StatusOK {
+				w.WriteHeader(http.StatusCreated)
+			} else {
+				w.WriteHeader(status)
+			}
+			_, _ = fmt.Fprint(w, `{"number":7,"html_url":"https://github.com/owner/repo/issues/7","state":"open"}`)
+		})
+		mux.HandleFunc("/repos/owner/repo/issues/7", func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(status)
+			_, _ = fmt.Fprint(w, `{"number":7,"html_url":"https://github.com/owner/repo/issues/7","state":"closed"}`)
+		})
+		server = httptest.NewServer(mux)
+		client = &GitHubClient{
+			HttpClient: &httpClient.HttpClient{Client: server.Client(), TokenKey: "issue-token"},
+			endpoint:   endpoint{baseURL: server.URL},
+		}
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	It("should create, update and close an issue", func() {
+		ctx := context.Background()
+		githubIssue := &maromdanaiov1alpha1.GitHubIssue{Status: maromdanaiov1alpha1.GitHubIssueStatus{Number: 7}}
+
+		created, err := client.CreateIssue(ctx, "owner", "repo", &maromdanaiov1alpha1.IssueRequest{Title: "Test Issue"}, logr.Discard())
+		Expect(err).NotTo(HaveOccurred())
+		Expect(created.Number).To(Equal(7))
+
+		updated, err := client.UpdateIssue(ctx, "owner", "repo", 7, &maromdanaiov1alpha1.IssueRequest{Title: "Test Issue"}, logr.Discard())
+		Expect(err).NotTo(HaveOccurred())
+		Expect(updated.State).To(Equal("closed"))
+
+		Expect(client.CloseIssue(ctx, "owner", "repo", githubIssue, "completed", logr.Discard())).To(Succeed())
+	})
+
+	It("should return an error instead of an empty issue when GitHub rejects the write", func() {
+		ctx := context.Background()
+		githubIssue := &maromdanaiov1alpha1.GitHubIssue{Status: maromdanaiov1alpha1.GitHubIssueStatus{Number: 7}}
+
+		for _, status = range []int{http.StatusForbidden, http.StatusNotFound, http.StatusGone, http.StatusUnprocessableEntity} {
+			created, err := client.CreateIssue(ctx, "owner", "repo", &maromdanaiov1alpha1.IssueRequest{Title: "Test Issue"}, logr.Discard())
+			Expect(err).To(MatchError(ContainSubstring(fmt.Sprintf("status code: %d", status))))
+			Expect(created).To(BeNil())
+
+			updated, err := client.UpdateIssue(ctx, "owner", "repo", 7, &maromdanaiov1alpha1.IssueRequest{Title: "Test Issue"}, logr.Discard())
+			Expect(err).To(MatchError(ContainSubstring(fmt.Sprintf("status code: %d", status))))
+			Expect(updated).To(BeNil())
+
+			Expect(client.CloseIssue(ctx, "owner", "repo", githubIssue, "completed", logr.Discard())).To(MatchError(ContainSubstring(fmt.Sprintf("status code: %d", status))))
+		}
+	})
+})
+
+var _ = Describe("Comments", func() {
+	var (
+		server *httptest.Server
+		client *GitHubClient
+		body   string
+	)
+
+	BeforeEach(func() {
+		body = "First body"
+		mux := http.NewServeMux()
+		mux.HandleFunc("/repos/owner/repo/issues/7/comments", func(w http.ResponseWriter, r *http.Request) {
+			if r.Method == http.MethodGet {
+				_, _ = fmt.Fprintf(w, `[{"id":41,"body":"Other comment"},{"id":42,"body":%q}]`, body)
+				return
+			}
+			w.WriteHeader(http.StatusCreated)
+			_, _ = fmt.Fprintf(w, `{"id":42,"html_url":"https://github.com/owner/repo/issues/7#issuecomment-42","body":%q}`, body)
+		})
+		mux.HandleFunc("/repos/owner/repo/issues/comments/42", func(w http.ResponseWriter, r *http.Request) {
+			switch r.Method {
+			case http.MethodPatch:
+				body = "Second body"
+			case http.MethodDelete:
+				w.WriteHeader(http.StatusNoContent)
+				return
+			}
+			_, _ = fmt.Fprintf(w, `{"id":42,"html_url":"https://github.com/owner/repo/issues/7#issuecomment-42","body":%q}`, body)
+		})
+		server = httptest.NewServer(mux)
+		client = &GitHubClient{
+			HttpClient: &httpClient.HttpClient{Client: server.Client(), TokenKey: "comment-token"},
+			endpoint:   endpoint{baseURL: server.URL},
+		}
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	It("should create, get, list, update and delete a comment", func() {
+		ctx := context.Background()
+
+		created, err := client.CreateComment(ctx, "owner", "repo", 7, body, logr.Discard())
+		Expect(err).NotTo(HaveOccurred())
+		Expect(created.ID).To(Equal(int64(42)))
+		Expect(created.HTMLURL).To(Equal("https://github.com/owner/repo/issues/7#issuecomment-42"))
+
+		updated, err := client.UpdateComment(ctx, "owner", "repo", 42, "Second body", logr.Discard())
+		Expect(err).NotTo(HaveOccurred())
+		Expect(updated.Body).To(Equal("Second body"))
+
+		found, err := client.GetComment(ctx, "owner", "repo", 42, logr.Discard())
+		Expect(err).NotTo(HaveOccurred())
+		Expect(found.Body).To(Equal("Second body"))
+
+		comments, err := client.ListComments(ctx, "owner", "repo", 7, logr.Discard())
+		Expect(err).NotTo(HaveOccurred())
+		Expect(comments).To(HaveLen(2))
+		Expect(comments[1].ID).To(Equal(int64(42)))
+		Expect(comments[1].Body).To(Equal("Second body"))
+
+		Expect(client.DeleteComment(ctx, "owner", "repo", 42, logr.Discard())).To(Succeed())
+
+		missing, err := client.GetComment(ctx, "owner", "repo", 43, logr.Discard())
+		Expect(err).NotTo(HaveOccurred())
+		Expect(missing).To(BeNil())
+	})
+})
+
+var _ = Describe("Labels", func() {
+	var (
+		server   *httptest.Server
+		requests []string
+	)
+
+	BeforeEach(func() {
+		requests = nil
+		mux := http.NewServeMux()
+		mux.HandleFunc("/repos/owner/repo/labels", func(w http.ResponseWriter, r *http.Request) {
+			requests = append(requests, r.Method+" "+r.URL.Path)
+			if r.Method == http.MethodPost {
+				w.WriteHeader(http.StatusCreated)
+				return
+			}
+			if r.URL.Query().Get("page") == "2" {
+				_, _ = fmt.Fprint(w, `[{"name":"good first issue","color":"7057ff"}]`)
+				return
+			}
+			w.Header().Set("Link", fmt.Sprintf(`<%s/repos/owner/repo/labels?page=2>; rel="next"`, server.URL))
+			_, _ = fmt.Fprint(w, `[{"name":"bug","color":"d73a4a","description":"Something is broken"}]`)
+		})
+		mux.HandleFunc("/repos/owner/repo/labels/", func(w http.ResponseWriter, r *http.Request) {
+			requests = append(requests, r.Method+" "+r.URL.EscapedPath())
+			if r.Method == http.MethodDelete {
+				w.WriteHeader(http.StatusNoContent)
+			}
+		})
+		server = httptest.NewServer(mux)
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	It("should list, create, update and delete labels", func() {
+		ctx := context.Background()
+		client := &GitHubClient{
+			HttpClient: &httpClient.HttpClient{Client: server.Client(), TokenKey: "label-token"},
+			endpoint:   endpoint{baseURL: server.URL},
+		}
+
+		labels, err := client.ListLabels(ctx, "owner", "repo", logr.Discard())
+		Expect(err).NotTo(HaveOccurred())
+		Expect(labels).To(Equal([]maromdanaiov1alpha1.Label{
+			{Name: "bug", Color: "d73a4a", Description: "Something is broken"},
+			{Name: "good first issue", Color: "7057ff"},
+		}))
+
+		Expect(client.CreateLabel(ctx, "owner", "repo", &maromdanaiov1alpha1.LabelRequest{Name: "triage", Color: "000000"}, logr.Discard())).To(Succeed())
+		Expect(client.UpdateLabel(ctx, "owner", "repo", "good first issue", &maromdanaiov1alpha1.LabelRequest{NewName: "starter", Color: "7057ff"}, logr.Discard())).To(Succeed())
+		Expect(client.DeleteLabel(ctx, "owner", "repo", "bug", logr.Discard())).To(Succeed())
+		Expect(requests).To(Equal([]string{
+			"GET /repos/owner/repo/labels",
+			"GET /repos/owner/repo/labels",
+			"POST /repos/owner/repo/labels",
+			"PATCH /repos/owner/repo/labels/good%20first%20issue",
+			"DELETE /repos/owner/repo/labels/bug",
+		}))
+	})
+})
+
+var _ = Describe("Milestones", func() {
+	var (
+		server   *httptest.Server
+		requests []string
+	)
+
+	BeforeEach(func() {
+		requests = nil
+		mux := http.NewServeMux()
+		mux.HandleFunc("/repos/owner/repo/milestones", func(w http.ResponseWriter, r *http.Request) {
+			requests = append(requests, r.Method+" "+r.URL.Path)
+			if r.Method == http.MethodPost {
+				w.WriteHeader(http.StatusCreated)
+				_, _ = fmt.Fprint(w, `{"number":3,"title":"v2.0","state":"open"}`)
+				return
+			}
+			_, _ = fmt.Fprint(w, `[{"number":1,"title":"v1.0","state":"closed","open_issues":0,"closed_issues":5,"due_on":"2024-06-30T07:00:00Z"}]`)
+		})
+		mux.HandleFunc("/repos/owner/repo/milestones/", func(w http.ResponseWriter, r *http.Request) {
+			requests = append(requests, r.Method+" "+r.URL.Path)
+			if r.URL.Path == "/repos/owner/repo/milestones/9" {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			_, _ = fmt.Fprint(w, `{"number":1,"title":"v1.0","state":"open","open_issues":2,"closed_issues":5}`)
+		})
+		server = httptest.NewServer(mux)
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	It("should list, get, create and update milestones", func() {
+		ctx := context.Background()
+		client := &GitHubClient{
+			HttpClient: &httpClient.HttpClient{Client: server.Client(), TokenKey: "milestone-token"},
+			endpoint:   endpoint{baseURL: server.URL},
+		}
+
+		milestones, err := client.ListMilestones(ctx, "owner", "repo", logr.Discard())
+		Expect(err).NotTo(HaveOccurred())
+		Expect(milestones).To(HaveLen(1))
+		Expect(milestones[0].ClosedIssues).To(Equal(5))
+		Expect(milestones[0].DueOn).NotTo(BeNil())
+
+		missing, err := client.GetMilestone(ctx, "owner", "repo", 9, logr.Discard())
+		Expect(err).NotTo(HaveOccurred())
+		Expect(missing).To(BeNil())
+
+		created, err := client.CreateMilestone(ctx, "owner", "repo", &maromdanaiov1alpha1.MilestoneRequest{Title: "v2.0"}, logr.Discard())
+		Expect(err).NotTo(HaveOccurred())
+		Expect(created.Number).To(Equal(3))
+
+		updated, err := client.UpdateMilestone(ctx, "owner", "repo", 1, &maromdanaiov1alpha1.MilestoneRequest{Title: "v1.0", State: "open"}, logr.Discard())
+		Expect(err).NotTo(HaveOccurred())
+		Expect(updated.OpenIssues).To(Equal(2))
+		Expect(requests).To(Equal([]string{
+			"GET /repos/owner/repo/milestones",
+			"GET /repos/owner/repo/milestones/9",
+			"POST /repos/owner/repo/milestones",
+			"PATCH /repos/owner/repo/milestones/1",
+		}))
+	})
+})
@@ -0,0 +1,183 @@
+package http
+
+import (
+	"bytes"
+	"container/list"
+	"io"
+	"net/http"
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+var (
+	etag            = "ETag"
+	lastModified    = "Last-Modified"
+	ifNoneMatch     = "If-None-Match"
+	ifModifiedSince = "If-Modified-Since"
+	cacheHit        = "hit"
+	cacheMiss       = "miss"
+	// DefaultCacheSize is the default number of bytes of response bodies kept by the cache
+	DefaultCacheSize = 32 << 20
+
+	responses = newResponseCache(DefaultCacheSize)
+
+	cacheRequestsCounter = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "github_http_cache_requests_total",
+		Help: "The number of conditional GitHub requests, by whether the cached response was served",
+	}, []string{"result"})
+)
+
+func init() {
+	metrics.Registry.MustRegister(cacheRequestsCounter)
+}
+
+// SetCacheSize sets the number of bytes of response bodies kept by the cache, zero disables the cache.
+func SetCacheSize(size int) {
+	responses.resize(size)
+}
+
+// cachedResponse is a response kept to be served again when GitHub answers 304 Not Modified.
+type cachedResponse struct {
+	key          string
+	etag         string
+	lastModified string
+	header       http.Header
+	body         []byte
+}
+
+// response returns a new response serving the cached body.
+func (c *cachedResponse) response(req *http.Request) *http.Response {
+	return &http.Response{
+		Status:        "200 OK",
+		StatusCode:    http.StatusOK,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        c.header.Clone(),
+		Body:          io.NopCloser(bytes.NewReader(c.body)),
+		ContentLength: int64(len(c.body)),
+		Request:       req,
+	}
+}
+
+// responseCache is a least recently used cache of responses, bounded by the size of their bodies.
+type responseCache struct {
+	mutex   sync.Mutex
+	maxSize int
+	size    int
+	entries map[string]*list.Element
+	order   *list.List
+}
+
+func newResponseCache(maxSize int) *responseCache {
+	return &responseCache{
+		maxSize: maxSize,
+		entries: map[string]*list.Element{},
+		order:   list.New(),
+	}
+}
+
+// get returns the cached response for the key, or nil if there is none.
+func (c *responseCache) get(key string) *cachedResponse {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	element, ok := c.entries[key]
+	if !ok {
+		return nil
+	}
+	c.order.MoveToFront(element)
+	return element.Value.(*cachedResponse)
+}
+
+// add caches the response, evicting the least recently used responses to stay under the maximum size.
+func (c *responseCache) add(entry *cachedResponse) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if element, ok := c.entries[entry.key]; ok {
+		c.remove(element)
+	}
+	if len(entry.body) > c.maxSize {
+		return
+	}
+
+	c.entries[entry.key] = c.order.PushFront(entry)
+	c.size += len(entry.body)
+	c.evict()
+}
+
+// resize changes the maximum size of the cache.
+func (c *responseCache) resize(maxSize int) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.maxSize = maxSize
+	c.evict()
+}
+
+// evict removes the least recently used responses until the cache is under its maximum size.
+func (c *responseCache) evict() {
+	for c.size > c.maxSize {
+		c.remove(c.order.Back())
+	}
+}
+
+// remove removes the element from the cache.
+func (c *responseCache) remove(element *list.Element) {
+	entry := c.order.Remove(element).(*cachedResponse)
+	delete(c.entries, entry.key)
+	c.size -= len(entry.body)
+}
+
+// cacheKey returns the key the response to a GET request is cached by.
+// Responses depend on what the token can see, so they are cached per token.
+func (r *HttpClient) cacheKey(url string) string {
+	return r.TokenKey + " " + url
+}
+
+// setConditionalHeaders makes the request conditional on the cached response having changed.
+func setConditionalHeaders(req *http.Request, cached *cachedResponse) {
+	if cached.etag != "" {
+		req.Header.Set(ifNoneMatch, cached.etag)
+	}
+	if cached.lastModified != "" {
+		req.Header.Set(ifModifiedSince, cached.lastModified)
+	}
+}
+
+// cacheResponse serves the cached response when GitHub answers 304 Not Modified,
+// and caches successful responses that can be validated later.
+func cacheResponse(key string, req *http.Request, response *http.Response, cached *cachedResponse) (*http.Response, error) {
+	if response.StatusCode == http.StatusNotModified && cached != nil {
+		response.Body.Close()
+		cacheRequestsCounter.WithLabelValues(cacheHit).Inc()
+		return cached.response(req), nil
+	}
+	cacheRequestsCounter.WithLabelValues(cacheMiss).Inc()
+
+	if response.StatusCode != http.StatusOK {
+		return response, nil
+	}
+	entry := &cachedResponse{
+		key:          key,
+		etag:         response.Header.Get(etag),
+		lastModified: response.Header.Get(lastModified),
+		header:       response.Header.Clone(),
+	}
+	if entry.etag == "" && entry.lastModified == "" {
+		return response, nil
+	}
+
+	body, err := io.ReadAll(response.Body)
+	response.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	entry.body = body
+	response.Body = io.NopCloser(bytes.NewReader(body))
+	responses.add(entry)
+	return response, nil
+}
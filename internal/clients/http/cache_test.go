@@ -0,0 +1,65 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package http
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Response cache", func() {
+	It("should serve the cached body when GitHub answers 304 Not Modified", func() {
+		requests := 0
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			requests++
+			if r.Header.Get(ifNoneMatch) == `"v1"` {
+				w.WriteHeader(http.StatusNotModified)
+				return
+			}
+			w.Header().Set(etag, `"v1"`)
+			_, _ = w.Write([]byte(`[{"number":1}]`))
+		}))
+		defer server.Close()
+
+		client := &HttpClient{Client: server.Client(), TokenKey: "cached-token"}
+		for i := 0; i < 2; i++ {
+			response, err := client.SendRequest(server.URL+"/repos/owner/repo/issues", http.MethodGet, nil)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(response.StatusCode).To(Equal(http.StatusOK))
+			body, err := io.ReadAll(response.Body)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(body)).To(Equal(`[{"number":1}]`))
+		}
+		Expect(requests).To(Equal(2))
+	})
+
+	It("should evict the least recently used responses to stay under its size", func() {
+		cache := newResponseCache(10)
+		cache.add(&cachedResponse{key: "first", body: make([]byte, 6)})
+		cache.add(&cachedResponse{key: "second", body: make([]byte, 6)})
+
+		Expect(cache.get("first")).To(BeNil())
+		Expect(cache.get("second")).NotTo(BeNil())
+
+		cache.add(&cachedResponse{key: "too-big", body: make([]byte, 11)})
+		Expect(cache.get("too-big")).To(BeNil())
+	})
+})
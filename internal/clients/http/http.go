@@ -1,9 +1,17 @@
 package http
 
 import (
+	"bytes"
 	"encoding/json"
+	"errors"
+	"io"
 	"net/http"
+	"strconv"
 	"strings"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
 )
 
 var (
@@ -11,31 +19,110 @@ var (
 	acceptValue      = "application/vnd.github.v3+json"
 	contentType      = "Content-Type"
 	contentTypeValue = "application/json"
+
+	// ErrUnauthorized is returned when GitHub rejects the credentials of the request
+	ErrUnauthorized = errors.New("GitHub rejected the credentials")
+
+	requestError = "error"
+
+	requestDurationHistogram = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "github_api_request_duration_seconds",
+		Help:    "The latency of the GitHub API requests, by endpoint, method and status code",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"endpoint", "method", "status"})
 )
 
+func init() {
+	metrics.Registry.MustRegister(requestDurationHistogram)
+}
+
 type HttpClient struct {
 	Client *http.Client
+	// TokenKey identifies the token the client authenticates with, the rate limit budget is tracked per token key
+	TokenKey string
 }
 
 // SendRequest sends a request to github.
+// GET requests are sent conditionally on the cached response, which is served when GitHub answers 304 Not Modified.
+// Requests are not sent while the rate limit of the token is exhausted, a RateLimitError is returned instead.
+// ErrUnauthorized is returned when GitHub rejects the token.
 func (r *HttpClient) SendRequest(url string, method string, body interface{}) (*http.Response, error) {
-
-	requestBody, err := json.Marshal(body)
-	if err != nil {
+	if err := r.checkRateLimit(time.Now()); err != nil {
 		return nil, err
 	}
 
-	req, err := http.NewRequest(method, url, strings.NewReader(string(requestBody)))
+	// Requests without a body, such as GET and DELETE, are sent without one rather than with a JSON null.
+	var requestBody io.Reader
+	if body != nil {
+		encoded, err := json.Marshal(body)
+		if err != nil {
+			return nil, err
+		}
+		requestBody = bytes.NewReader(encoded)
+	}
+
+	req, err := http.NewRequest(method, url, requestBody)
 	if err != nil {
 		return nil, err
 	}
 	req.Header.Set(accept, acceptValue)
-	req.Header.Set(contentType, contentTypeValue)
+	if body != nil {
+		req.Header.Set(contentType, contentTypeValue)
+	}
 
+	var cached *cachedResponse
+	key := r.cacheKey(url)
+	if method == http.MethodGet {
+		if cached = responses.get(key); cached != nil {
+			setConditionalHeaders(req, cached)
+		}
+	}
+
+	start := time.Now()
 	response, err := r.Client.Do(req)
+	status := requestError
+	if err == nil {
+		status = strconv.Itoa(response.StatusCode)
+	}
+	requestDurationHistogram.WithLabelValues(endpointLabel(req.URL.Path), method, status).Observe(time.Since(start).Seconds())
 	if err != nil {
 		return nil, err
 	}
 
+	if err := r.recordRateLimit(response, time.Now()); err != nil {
+		response.Body.Close()
+		return nil, err
+	}
+	if response.StatusCode == http.StatusUnauthorized {
+		response.Body.Close()
+		return nil, ErrUnauthorized
+	}
+
+	if method == http.MethodGet {
+		return cacheResponse(key, req, response, cached)
+	}
+
 	return response, nil
 }
+
+// endpointLabel returns the path of the endpoint with its owner, repo, numbers and names replaced by placeholders,
+// so requests for different issues are counted under the same endpoint.
+func endpointLabel(path string) string {
+	segments := strings.Split(strings.Trim(path, "/"), "/")
+	for i := 0; i < len(segments); i++ {
+		switch {
+		case segments[i] == "repos" && i+2 < len(segments):
+			segments[i+1] = "{owner}"
+			segments[i+2] = "{repo}"
+			i += 2
+		case segments[i] == "labels" && i+1 < len(segments):
+			segments[i+1] = "{name}"
+			i++
+		default:
+			if _, err := strconv.Atoi(segments[i]); err == nil {
+				segments[i] = "{number}"
+			}
+		}
+	}
+	return "/" + strings.Join(segments, "/")
+}
@@ -0,0 +1,69 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package http
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Send request", func() {
+	It("should return ErrUnauthorized when GitHub rejects the token", func() {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusUnauthorized)
+		}))
+		defer server.Close()
+
+		client := &HttpClient{Client: server.Client(), TokenKey: "revoked-token"}
+		_, err := client.SendRequest(server.URL, http.MethodPost, nil)
+		Expect(err).To(MatchError(ErrUnauthorized))
+	})
+
+	It("should only send a JSON body when the request has one", func() {
+		var bodies []string
+		var contentTypes []string
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			body, err := io.ReadAll(r.Body)
+			Expect(err).NotTo(HaveOccurred())
+			bodies = append(bodies, string(body))
+			contentTypes = append(contentTypes, r.Header.Get(contentType))
+			w.WriteHeader(http.StatusNoContent)
+		}))
+		defer server.Close()
+
+		client := &HttpClient{Client: server.Client(), TokenKey: "body-token"}
+		response, err := client.SendRequest(server.URL, http.MethodDelete, nil)
+		Expect(err).NotTo(HaveOccurred())
+		response.Body.Close()
+		response, err = client.SendRequest(server.URL, http.MethodPost, map[string]string{"title": "Issue"})
+		Expect(err).NotTo(HaveOccurred())
+		response.Body.Close()
+
+		Expect(bodies).To(Equal([]string{"", `{"title":"Issue"}`}))
+		Expect(contentTypes).To(Equal([]string{"", contentTypeValue}))
+	})
+
+	It("should label the request duration with the endpoint, not the owner, repo or numbers", func() {
+		Expect(endpointLabel("/repos/octo/hello/issues/42/timeline")).To(Equal("/repos/{owner}/{repo}/issues/{number}/timeline"))
+		Expect(endpointLabel("/api/v3/repos/octo/hello/labels/good first issue")).To(Equal("/api/v3/repos/{owner}/{repo}/labels/{name}"))
+		Expect(endpointLabel("/app/installations/7/access_tokens")).To(Equal("/app/installations/{number}/access_tokens"))
+	})
+})
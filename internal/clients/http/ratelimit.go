@@ -0,0 +1,124 @@
+package http
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+var (
+	rateLimitRemaining = "X-RateLimit-Remaining"
+	rateLimitReset     = "X-RateLimit-Reset"
+	retryAfter         = "Retry-After"
+	secondaryRateLimit = "secondary rate limit"
+	// secondaryRateLimitWait is how long GitHub asks to wait after a secondary rate limit without a Retry-After header
+	secondaryRateLimitWait = time.Minute
+
+	rateLimitsMutex sync.Mutex
+	rateLimits      = map[string]*rateLimit{}
+
+	rateLimitRemainingGauge = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "github_rate_limit_remaining",
+		Help: "The number of requests left in the GitHub rate limit window of the token",
+	}, []string{"token"})
+)
+
+func init() {
+	metrics.Registry.MustRegister(rateLimitRemainingGauge)
+}
+
+// RateLimitError is returned when the GitHub rate limit of the token is exhausted, until ResetAt.
+type RateLimitError struct {
+	ResetAt   time.Time
+	Secondary bool
+}
+
+func (e *RateLimitError) Error() string {
+	if e.Secondary {
+		return fmt.Sprintf("GitHub secondary rate limit hit, retrying after %s", e.ResetAt.Format(time.RFC3339))
+	}
+	return fmt.Sprintf("GitHub rate limit exhausted, retrying after %s", e.ResetAt.Format(time.RFC3339))
+}
+
+// rateLimit tracks the rate limit budget of a token.
+type rateLimit struct {
+	remaining    int
+	resetAt      time.Time
+	blockedUntil time.Time
+	secondary    bool
+}
+
+// tokenRateLimit returns the rate limit of the token, shared between all the clients using it.
+func tokenRateLimit(tokenKey string) *rateLimit {
+	limit, ok := rateLimits[tokenKey]
+	if !ok {
+		limit = &rateLimit{remaining: -1}
+		rateLimits[tokenKey] = limit
+	}
+	return limit
+}
+
+// checkRateLimit returns a RateLimitError if the token has no budget left, so no request is wasted on GitHub.
+func (r *HttpClient) checkRateLimit(now time.Time) error {
+	rateLimitsMutex.Lock()
+	defer rateLimitsMutex.Unlock()
+
+	limit := tokenRateLimit(r.TokenKey)
+	if now.Before(limit.blockedUntil) {
+		return &RateLimitError{ResetAt: limit.blockedUntil, Secondary: limit.secondary}
+	}
+	if limit.remaining == 0 && now.Before(limit.resetAt) {
+		return &RateLimitError{ResetAt: limit.resetAt}
+	}
+	return nil
+}
+
+// recordRateLimit records the rate limit headers of the response,
+// and returns a RateLimitError if the request was rejected for exceeding the primary or secondary rate limit.
+func (r *HttpClient) recordRateLimit(response *http.Response, now time.Time) error {
+	rateLimitsMutex.Lock()
+	defer rateLimitsMutex.Unlock()
+
+	limit := tokenRateLimit(r.TokenKey)
+	if remaining, err := strconv.Atoi(response.Header.Get(rateLimitRemaining)); err == nil {
+		limit.remaining = remaining
+		rateLimitRemainingGauge.WithLabelValues(r.TokenKey).Set(float64(remaining))
+	}
+	if reset, err := strconv.ParseInt(response.Header.Get(rateLimitReset), 10, 64); err == nil {
+		limit.resetAt = time.Unix(reset, 0)
+	}
+
+	if response.StatusCode != http.StatusForbidden && response.StatusCode != http.StatusTooManyRequests {
+		return nil
+	}
+
+	if seconds, err := strconv.Atoi(response.Header.Get(retryAfter)); err == nil {
+		limit.blockedUntil = now.Add(time.Duration(seconds) * time.Second)
+		limit.secondary = limit.remaining != 0
+		return &RateLimitError{ResetAt: limit.blockedUntil, Secondary: limit.secondary}
+	}
+	if limit.remaining == 0 {
+		return &RateLimitError{ResetAt: limit.resetAt}
+	}
+
+	// A 403 is also returned for permission errors, only the body tells them apart from secondary rate limits.
+	body, err := io.ReadAll(response.Body)
+	if err != nil {
+		return err
+	}
+	response.Body = io.NopCloser(bytes.NewReader(body))
+	if strings.Contains(strings.ToLower(string(body)), secondaryRateLimit) {
+		limit.blockedUntil = now.Add(secondaryRateLimitWait)
+		limit.secondary = true
+		return &RateLimitError{ResetAt: limit.blockedUntil, Secondary: true}
+	}
+	return nil
+}
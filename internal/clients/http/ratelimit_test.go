@@ -0,0 +1,76 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package http
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Rate limit", func() {
+	var (
+		server   *httptest.Server
+		requests int
+	)
+
+	BeforeEach(func() {
+		requests = 0
+		server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			requests++
+			w.Header().Set(rateLimitReset, strconv.FormatInt(time.Now().Add(time.Hour).Unix(), 10))
+			switch requests {
+			case 1:
+				w.Header().Set(rateLimitRemaining, "0")
+				w.WriteHeader(http.StatusOK)
+			case 2:
+				w.Header().Set(rateLimitRemaining, "10")
+				w.WriteHeader(http.StatusForbidden)
+				_, _ = w.Write([]byte(`{"message":"You have exceeded a secondary rate limit."}`))
+			default:
+				w.WriteHeader(http.StatusOK)
+			}
+		}))
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	It("should stop sending requests once the token budget is exhausted", func() {
+		client := &HttpClient{Client: server.Client(), TokenKey: "exhausted-token"}
+
+		_, err := client.SendRequest(server.URL, http.MethodGet, nil)
+		Expect(err).NotTo(HaveOccurred())
+
+		_, err = client.SendRequest(server.URL, http.MethodGet, nil)
+		var rateLimitErr *RateLimitError
+		Expect(err).To(BeAssignableToTypeOf(rateLimitErr))
+		Expect(requests).To(Equal(1))
+
+		By("Hitting the secondary rate limit with another token")
+		other := &HttpClient{Client: server.Client(), TokenKey: "other-token"}
+		_, err = other.SendRequest(server.URL, http.MethodGet, nil)
+		Expect(err).To(HaveOccurred())
+		Expect(err.(*RateLimitError).Secondary).To(BeTrue())
+		Expect(requests).To(Equal(2))
+	})
+})
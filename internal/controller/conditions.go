@@ -0,0 +1,102 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"errors"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	maromdanaiov1alpha1 "my.domain/githubissue/api/v1alpha1"
+	httpClient "my.domain/githubissue/internal/clients/http"
+)
+
+const (
+	// ready summarises the CredentialsValid, RateLimited and Synced conditions, so kubectl wait --for=condition=Ready can be used
+	ready              = "Ready"
+	issueReady         = "IssueReady"
+	credentialsInvalid = "CredentialsInvalid"
+	notSynced          = "NotSynced"
+	notSyncedMessage   = "Issue has not been synced with GitHub yet"
+
+	synced             = "Synced"
+	issueSynced        = "IssueSynced"
+	issueSyncedMessage = "Issue is synced with GitHub"
+	syncFailed         = "SyncFailed"
+)
+
+// setCondition sets the condition, observed at the current generation of the GitHubIssue, and refreshes the Ready condition.
+// The transition time of the condition only changes when its status does. It returns whether the conditions changed.
+func setCondition(githubIssue *maromdanaiov1alpha1.GitHubIssue, condition metav1.Condition) bool {
+	condition.ObservedGeneration = githubIssue.Generation
+	changed := meta.SetStatusCondition(&githubIssue.Status.Conditions, condition)
+	return setReadyCondition(githubIssue) || changed
+}
+
+// setNotSynced reports in the Synced condition why the issue was not synced with GitHub.
+func setNotSynced(githubIssue *maromdanaiov1alpha1.GitHubIssue, reason string, message string) {
+	setCondition(githubIssue, metav1.Condition{
+		Type:    synced,
+		Status:  metav1.ConditionFalse,
+		Reason:  reason,
+		Message: message,
+	})
+}
+
+// setReadyCondition sets the Ready condition from the CredentialsValid, RateLimited and Synced conditions.
+func setReadyCondition(githubIssue *maromdanaiov1alpha1.GitHubIssue) bool {
+	conditions := githubIssue.Status.Conditions
+	condition := metav1.Condition{
+		Type:               ready,
+		Status:             metav1.ConditionFalse,
+		ObservedGeneration: githubIssue.Generation,
+	}
+	syncedCondition := meta.FindStatusCondition(conditions, synced)
+	switch {
+	case meta.IsStatusConditionFalse(conditions, credentialsValid):
+		condition.Reason = credentialsInvalid
+		condition.Message = meta.FindStatusCondition(conditions, credentialsValid).Message
+	case meta.IsStatusConditionTrue(conditions, rateLimited):
+		condition.Reason = rateLimited
+		condition.Message = meta.FindStatusCondition(conditions, rateLimited).Message
+	case syncedCondition == nil:
+		condition.Reason = notSynced
+		condition.Message = notSyncedMessage
+	case syncedCondition.Status != metav1.ConditionTrue:
+		condition.Reason = notSynced
+		condition.Message = syncedCondition.Message
+	default:
+		condition.Status = metav1.ConditionTrue
+		condition.Reason = issueReady
+		condition.Message = issueSyncedMessage
+	}
+	return meta.SetStatusCondition(&githubIssue.Status.Conditions, condition)
+}
+
+// setSyncFailed reports the failure to sync the issue with GitHub in the Synced condition.
+// Rate limit and authorization failures are left to Reconcile, which reports them in their own conditions.
+func (r *GitHubIssueReconciler) setSyncFailed(ctx context.Context, githubIssue *maromdanaiov1alpha1.GitHubIssue, err error) {
+	var rateLimitErr *httpClient.RateLimitError
+	if errors.As(err, &rateLimitErr) || errors.Is(err, httpClient.ErrUnauthorized) {
+		return
+	}
+	setNotSynced(githubIssue, syncFailed, err.Error())
+	if err := r.Status().Update(ctx, githubIssue); err != nil {
+		r.Logger.Error(err, "Failed to update GitHubIssue status")
+	}
+}
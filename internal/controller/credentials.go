@@ -0,0 +1,30 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// credentialsReader returns the reader the credentials secrets are read with, shared by all the reconcilers.
+// The API reader is preferred so only get access to secrets is needed, the client is used when it is not set.
+func credentialsReader(apiReader client.Reader, c client.Client) client.Reader {
+	if apiReader != nil {
+		return apiReader
+	}
+	return c
+}
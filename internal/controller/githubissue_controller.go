@@ -19,29 +19,105 @@ package controller
 import (
 	"context"
 	"errors"
+	"fmt"
 	"strings"
+	"time"
 
 	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
 	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
 	maromdanaiov1alpha1 "my.domain/githubissue/api/v1alpha1"
 	"my.domain/githubissue/internal/clients/git"
+	httpClient "my.domain/githubissue/internal/clients/http"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 )
 
 const (
-	finalizer        = "githubIssue.finalizers.my.domain"
-	issueHasPr       = "IssueHasPR"
-	hasPrLink        = "IssueHasAPRLink"
-	hasPrMessage     = "Issue has a PR"
-	hasNoPr          = "IssueHasNoPR"
-	hasNoPrMessage   = "Issue does not have a PR"
-	openIssue        = "OpenIssue"
-	issueExists      = "IssueExists"
-	openIssueMessage = "Issue is open"
+	finalizer          = "githubIssue.finalizers.my.domain"
+	issueHasPr         = "IssueHasPR"
+	hasPrLink          = "IssueHasAPRLink"
+	hasPrMessage       = "Issue has a PR"
+	hasMergedPr        = "IssueHasAMergedPR"
+	merged             = "merged"
+	hasNoPr            = "IssueHasNoPR"
+	hasNoPrMessage     = "Issue does not have a PR"
+	openIssue          = "OpenIssue"
+	issueExists        = "IssueExists"
+	openIssueMessage   = "Issue is open"
+	open               = "open"
+	closed             = "closed"
+	issueClosed        = "IssueClosed"
+	closedIssueMessage = "Issue is closed"
+	completed          = "completed"
+	notPlanned         = "not_planned"
+	resolved           = "resolved"
+
+	deletionPolicyApplied = "DeletionPolicyApplied"
+	deletionPolicyFailed  = "DeletionPolicyFailed"
+	deletionGracePeriod   = 10 * time.Minute
+	// deletionCommentPosted records the deletion comment was posted, so retries of a failing deletion policy don't post it again
+	deletionCommentPosted = "DeletionCommentPosted"
+
+	credentialsValid           = "CredentialsValid"
+	credentialsResolved        = "CredentialsResolved"
+	credentialsResolvedMessage = "GitHub credentials were resolved"
+	credentialsUnreadable      = "CredentialsUnreadable"
+	secretNotFound             = "SecretNotFound"
+	secretKeyNotFound          = "SecretKeyNotFound"
+	invalidEndpoint            = "InvalidEndpoint"
+
+	rateLimited                = "RateLimited"
+	rateLimitExceeded          = "RateLimitExceeded"
+	secondaryRateLimitExceeded = "SecondaryRateLimitExceeded"
+	withinRateLimit            = "WithinRateLimit"
+	withinRateLimitMessage     = "GitHub rate limit is not exhausted"
+	minRateLimitRequeueDelay   = time.Second
+
+	drifted        = "Drifted"
+	driftDetected  = "DriftDetected"
+	driftCorrected = "DriftCorrected"
+	inSync         = "InSync"
+	inSyncMessage  = "Issue matches the spec"
+
+	titleField       = "title"
+	bodyField        = "body"
+	stateField       = "state"
+	stateReasonField = "stateReason"
+	labelsField      = "labels"
+	assigneesField   = "assignees"
+	milestoneField   = "milestone"
+
+	issueCreated     = "IssueCreated"
+	issueUpdated     = "IssueUpdated"
+	issueReopened    = "IssueReopened"
+	issueAdopted     = "IssueAdopted"
+	issueTransferred = "IssueTransferred"
+	issueLocked      = "IssueLocked"
+	unauthorized     = "Unauthorized"
+
+	milestoneResolved    = "MilestoneResolved"
+	milestoneNotReady    = "MilestoneNotReady"
+	milestoneInOtherRepo = "MilestoneInOtherRepo"
+	// milestoneNotReadyRequeueDelay is how long to wait for the referenced GitHubMilestone to create its milestone
+	milestoneNotReadyRequeueDelay = 30 * time.Second
+)
+
+var (
+	errDeletionHandled = errors.New("GitHubIssue CR deletion has been handled")
+	errAlreadyDeleted  = errors.New("GitHubIssue CR may have been deleted")
+
+	errMilestoneNotReady    = errors.New("the referenced GitHubMilestone has not created its milestone yet")
+	errMilestoneInOtherRepo = errors.New("the referenced GitHubMilestone is in another repo")
+	errInvalidRepo          = errors.New("repo is not an owner/repo")
 )
 
 // GitHubIssueReconciler reconciles a GitHubIssue object
@@ -49,11 +125,23 @@ type GitHubIssueReconciler struct {
 	client.Client
 	Scheme *runtime.Scheme
 	Logger logr.Logger
+	// APIReader reads the credentials secrets straight from the API server, so only get access to secrets is needed.
+	// When it is not set the client is used.
+	APIReader client.Reader
+	// Recorder emits the events of the actions taken on GitHub, no events are emitted when it is not set.
+	Recorder record.EventRecorder
+	// ClusterID identifies the cluster in the ownership markers of the issues,
+	// so operators in different clusters managing the same repo do not adopt each other's issues.
+	ClusterID string
 }
 
 //+kubebuilder:rbac:groups=marom.dana.io.dana.io,resources=githubissues,verbs=get;list;watch;create;update;patch;delete
 //+kubebuilder:rbac:groups=marom.dana.io.dana.io,resources=githubissues/status,verbs=get;update;patch
 //+kubebuilder:rbac:groups=marom.dana.io.dana.io,resources=githubissues/finalizers,verbs=update
+//+kubebuilder:rbac:groups=marom.dana.io.dana.io,resources=githubmilestones,verbs=get;list;watch
+//+kubebuilder:rbac:groups="",resources=secrets,verbs=get
+//+kubebuilder:rbac:groups="",resources=configmaps,verbs=get;list;watch
+//+kubebuilder:rbac:groups="",resources=events,verbs=create;patch
 
 // Reconcile is part of the main kubernetes reconciliation loop which aims to
 // move the current state of the cluster closer to the desired state.
@@ -71,39 +159,158 @@ func (r *GitHubIssueReconciler) Reconcile(ctx context.Context, req ctrl.Request)
 		return ctrl.Result{}, nil
 	}
 
-	initializer := &git.GitHubClientInitializer{HttpClient: r.Client}
-	gitClient, err := initializer.InitializeGit(ctx)
+	result, err := r.reconcileGitHubIssue(ctx, githubIssue)
+
+	var rateLimitErr *httpClient.RateLimitError
+	if errors.As(err, &rateLimitErr) {
+		logger.Info("GitHub rate limit reached, requeueing", "resetAt", rateLimitErr.ResetAt)
+		r.setRateLimited(ctx, githubIssue, rateLimitErr)
+		return ctrl.Result{RequeueAfter: rateLimitRequeueDelay(rateLimitErr.ResetAt)}, nil
+	}
+	if errors.Is(err, httpClient.ErrUnauthorized) {
+		r.setCredentialsInvalid(ctx, githubIssue, err)
+	}
+	return result, err
+}
+
+// reconcileGitHubIssue moves the GitHub issue closer to the state described by the GitHubIssue.
+func (r *GitHubIssueReconciler) reconcileGitHubIssue(ctx context.Context, githubIssue *maromdanaiov1alpha1.GitHubIssue) (ctrl.Result, error) {
+	previousStatus := githubIssue.Status.DeepCopy()
+	older, err := r.olderDuplicate(ctx, githubIssue)
+	if err != nil {
+		r.Logger.Error(err, "Failed to list GitHubIssues managing the same issue")
+		return ctrl.Result{}, err
+	}
+	if older != nil {
+		r.Logger.Info("Issue is already managed by an older GitHubIssue, not writing to it", "older", client.ObjectKeyFromObject(older))
+		return ctrl.Result{}, r.handleDuplicate(ctx, githubIssue, older)
+	}
+	if !githubIssue.DeletionTimestamp.IsZero() && !controllerutil.ContainsFinalizer(githubIssue, finalizer) {
+		return ctrl.Result{}, nil
+	}
+
+	initializer := &git.GitHubClientInitializer{HttpClient: r.secretReader()}
+	gitClient, err := initializer.InitializeGit(ctx, githubIssue.Namespace, githubIssue.Spec.CredentialsRef)
 
 	if err != nil {
 		r.Logger.Error(err, "Failed to initialize git clients")
+		r.setCredentialsInvalid(ctx, githubIssue, err)
+		if !githubIssue.DeletionTimestamp.IsZero() && !withinDeletionGracePeriod(githubIssue) {
+			// The credentials secret is often deleted with the namespace, which must not block the deletion forever.
+			r.Logger.Error(err, "Deletion grace period is over, removing the finalizer without applying the deletion policy",
+				"policy", githubIssue.Spec.DeletionPolicy)
+			controllerutil.RemoveFinalizer(githubIssue, finalizer)
+			return ctrl.Result{}, r.Update(ctx, githubIssue)
+		}
 		return ctrl.Result{}, err
 	}
+	setCondition(githubIssue, metav1.Condition{
+		Type:    credentialsValid,
+		Status:  metav1.ConditionTrue,
+		Reason:  credentialsResolved,
+		Message: credentialsResolvedMessage,
+	})
 
 	owner, repo := GetOwnerAndRepo(*githubIssue)
 
 	if err := r.CheckDeletion(ctx, githubIssue, owner, repo, gitClient); err != nil {
-		if errors.Is(errors.Unwrap(err), errors.New("NamespaceLabel CR deletion has been handled")) ||
-			errors.Is(errors.Unwrap(err), errors.New("GitHubIssue CR may have been deleted")) {
+		if errors.Is(err, errDeletionHandled) || errors.Is(err, errAlreadyDeleted) {
 			return ctrl.Result{}, nil
 		}
 		return ctrl.Result{}, err
 	}
+	if owner == "" || repo == "" {
+		err = fmt.Errorf("%w: %q", errInvalidRepo, githubIssue.Spec.Repo)
+		r.setSyncFailed(ctx, githubIssue, err)
+		return ctrl.Result{}, err
+	}
 
-	issues, err := gitClient.GetRepositoryIssues(owner, repo, r.Logger)
+	if err := r.resolveMilestoneRef(ctx, githubIssue); err != nil {
+		r.setMilestoneUnresolved(ctx, githubIssue, err)
+		switch {
+		case errors.Is(err, errMilestoneNotReady):
+			return ctrl.Result{RequeueAfter: milestoneNotReadyRequeueDelay}, nil
+		case errors.Is(err, errMilestoneInOtherRepo):
+			return ctrl.Result{}, nil
+		}
+		r.Logger.Error(err, "Failed to resolve milestone reference")
+		return ctrl.Result{}, err
+	}
+
+	if err := r.renderTemplate(ctx, githubIssue); err != nil {
+		r.setTemplateNotRendered(ctx, githubIssue, err)
+		if errors.Is(err, errTemplateNotFound) || errors.Is(err, errTemplateRenderFailed) || errors.Is(err, errDescriptionTooLong) {
+			return ctrl.Result{}, nil
+		}
+		r.Logger.Error(err, "Failed to render issue template")
+		return ctrl.Result{}, err
+	}
+
+	foundIssue, err := r.LookupIssue(ctx, owner, repo, githubIssue, gitClient)
 	if err != nil {
-		r.Logger.Error(err, "Failed to list all repository issues")
+		r.Logger.Error(err, "Failed to look up repository issue")
+		r.setSyncFailed(ctx, githubIssue, err)
 		return ctrl.Result{}, err
 	}
 
-	foundIssue := gitClient.FindIssue(issues, githubIssue.Spec.Title)
+	if foundIssue != nil && foundIssue.Number != githubIssue.Status.Number && !r.mayAdopt(githubIssue, foundIssue) {
+		r.Logger.Info("Issue with the same title was not created by the GitHubIssue, not adopting it", "number", foundIssue.Number)
+		r.setConflict(ctx, githubIssue, foundIssue)
+		return ctrl.Result{}, nil
+	}
+	setCondition(githubIssue, metav1.Condition{
+		Type:    conflict,
+		Status:  metav1.ConditionFalse,
+		Reason:  issueOwned,
+		Message: issueOwnedMessage,
+	})
+	if foundIssue != nil && foundIssue.Number != githubIssue.Status.Number {
+		r.recordEvent(githubIssue, corev1.EventTypeNormal, issueAdopted, "Adopted issue #%d %s", foundIssue.Number, foundIssue.HTMLURL)
+	}
+
+	var foundDrift []string
+	if foundIssue != nil {
+		foundDrift = issueDrift(githubIssue, foundIssue)
+	}
 
 	handledIssue, err := r.HandleIssues(foundIssue, ctx, owner, repo, githubIssue, gitClient)
 	if err != nil {
 		r.Logger.Error(err, "Failed to create/update issue")
+		r.setSyncFailed(ctx, githubIssue, err)
 		return ctrl.Result{}, err
 	}
 
+	recordIssue(githubIssue, handledIssue)
+	if handledIssue != nil {
+		pullRequests, err := gitClient.GetLinkedPullRequests(ctx, owner, repo, handledIssue.Number, r.Logger)
+		if err != nil {
+			r.Logger.Error(err, "Failed to get linked pull requests")
+			r.setSyncFailed(ctx, githubIssue, err)
+			return ctrl.Result{}, err
+		}
+		githubIssue.Status.PullRequests = pullRequests
+	}
 	r.updateConditions(githubIssue, handledIssue)
+	previousDrift := githubIssue.Status.DriftedFields
+	updateDriftCondition(githubIssue, foundDrift, handledIssue)
+	if drift := githubIssue.Status.DriftedFields; len(drift) > 0 && !sameSet(previousDrift, drift) {
+		r.recordEvent(githubIssue, corev1.EventTypeWarning, driftDetected, "Issue #%d %s differs from the spec: %s",
+			handledIssue.Number, handledIssue.HTMLURL, strings.Join(drift, ", "))
+	}
+	setCondition(githubIssue, metav1.Condition{
+		Type:    rateLimited,
+		Status:  metav1.ConditionFalse,
+		Reason:  withinRateLimit,
+		Message: withinRateLimitMessage,
+	})
+	setCondition(githubIssue, metav1.Condition{
+		Type:    synced,
+		Status:  metav1.ConditionTrue,
+		Reason:  issueSynced,
+		Message: issueSyncedMessage,
+	})
+	// HandleIssues returns the found issue itself when nothing was written to GitHub
+	recordSync(githubIssue, previousStatus, handledIssue != foundIssue, metav1.Now())
 
 	if err = r.Status().Update(ctx, githubIssue); err != nil {
 		r.Logger.Error(err, "Failed to update GitHubIssue status")
@@ -113,70 +320,493 @@ func (r *GitHubIssueReconciler) Reconcile(ctx context.Context, req ctrl.Request)
 	return ctrl.Result{}, nil
 }
 
+// setRateLimited reports the exhausted GitHub rate limit in the GitHubIssue conditions.
+func (r *GitHubIssueReconciler) setRateLimited(ctx context.Context, githubIssue *maromdanaiov1alpha1.GitHubIssue, rateLimitErr *httpClient.RateLimitError) {
+	reason := rateLimitExceeded
+	if rateLimitErr.Secondary {
+		reason = secondaryRateLimitExceeded
+	}
+	setCondition(githubIssue, metav1.Condition{
+		Type:    rateLimited,
+		Status:  metav1.ConditionTrue,
+		Reason:  reason,
+		Message: rateLimitErr.Error(),
+	})
+	setNotSynced(githubIssue, reason, rateLimitErr.Error())
+	r.recordEvent(githubIssue, corev1.EventTypeWarning, reason, "%s", rateLimitErr.Error())
+	if err := r.Status().Update(ctx, githubIssue); err != nil {
+		r.Logger.Error(err, "Failed to update GitHubIssue status")
+	}
+}
+
+// rateLimitRequeueDelay returns how long to wait before reconciling again, once the rate limit resets.
+func rateLimitRequeueDelay(resetAt time.Time) time.Duration {
+	delay := time.Until(resetAt)
+	if delay < minRateLimitRequeueDelay {
+		return minRateLimitRequeueDelay
+	}
+	return delay
+}
+
+// secretReader returns the reader used to read the credentials secrets.
+func (r *GitHubIssueReconciler) secretReader() client.Reader {
+	return credentialsReader(r.APIReader, r.Client)
+}
+
+// setCredentialsInvalid reports the failure to resolve the GitHub credentials in the GitHubIssue conditions.
+func (r *GitHubIssueReconciler) setCredentialsInvalid(ctx context.Context, githubIssue *maromdanaiov1alpha1.GitHubIssue, err error) {
+	reason := credentialsUnreadable
+	switch {
+	case errors.Is(err, git.ErrSecretNotFound):
+		reason = secretNotFound
+	case errors.Is(err, git.ErrSecretKeyNotFound):
+		reason = secretKeyNotFound
+	case errors.Is(err, git.ErrInvalidEndpoint):
+		reason = invalidEndpoint
+	case errors.Is(err, httpClient.ErrUnauthorized):
+		reason = unauthorized
+	}
+	setCondition(githubIssue, metav1.Condition{
+		Type:    credentialsValid,
+		Status:  metav1.ConditionFalse,
+		Reason:  reason,
+		Message: err.Error(),
+	})
+	setNotSynced(githubIssue, reason, err.Error())
+	r.recordEvent(githubIssue, corev1.EventTypeWarning, reason, "%s", err.Error())
+	if err := r.Status().Update(ctx, githubIssue); err != nil {
+		r.Logger.Error(err, "Failed to update GitHubIssue status")
+	}
+}
+
+// resolveMilestoneRef sets the number of the milestone of the GitHubMilestone referenced by MilestoneRef as the milestone of the spec.
+// The resolved number is only kept for this reconcile, the spec is never written back.
+func (r *GitHubIssueReconciler) resolveMilestoneRef(ctx context.Context, githubIssue *maromdanaiov1alpha1.GitHubIssue) error {
+	if githubIssue.Spec.MilestoneRef == "" {
+		meta.RemoveStatusCondition(&githubIssue.Status.Conditions, milestoneResolved)
+		return nil
+	}
+
+	milestone := &maromdanaiov1alpha1.GitHubMilestone{}
+	if err := r.Get(ctx, types.NamespacedName{Namespace: githubIssue.Namespace, Name: githubIssue.Spec.MilestoneRef}, milestone); err != nil {
+		if client.IgnoreNotFound(err) != nil {
+			return err
+		}
+		return fmt.Errorf("%w: GitHubMilestone %s not found", errMilestoneNotReady, githubIssue.Spec.MilestoneRef)
+	}
+	if !strings.EqualFold(milestone.Spec.Repo, githubIssue.Spec.Repo) {
+		return fmt.Errorf("%w: GitHubMilestone %s is in %s", errMilestoneInOtherRepo, milestone.Name, milestone.Spec.Repo)
+	}
+	if milestone.Status.Number == 0 {
+		return errMilestoneNotReady
+	}
+
+	githubIssue.Spec.Milestone = milestone.Status.Number
+	setCondition(githubIssue, metav1.Condition{
+		Type:    milestoneResolved,
+		Status:  metav1.ConditionTrue,
+		Reason:  milestoneResolved,
+		Message: fmt.Sprintf("GitHubMilestone %s is milestone #%d", milestone.Name, milestone.Status.Number),
+	})
+	return nil
+}
+
+// setMilestoneUnresolved reports the failure to resolve the referenced GitHubMilestone in the GitHubIssue conditions.
+func (r *GitHubIssueReconciler) setMilestoneUnresolved(ctx context.Context, githubIssue *maromdanaiov1alpha1.GitHubIssue, err error) {
+	reason := milestoneNotReady
+	if errors.Is(err, errMilestoneInOtherRepo) {
+		reason = milestoneInOtherRepo
+	}
+	setCondition(githubIssue, metav1.Condition{
+		Type:    milestoneResolved,
+		Status:  metav1.ConditionFalse,
+		Reason:  reason,
+		Message: err.Error(),
+	})
+	setNotSynced(githubIssue, reason, err.Error())
+	if err := r.Status().Update(ctx, githubIssue); err != nil {
+		r.Logger.Error(err, "Failed to update GitHubIssue status")
+	}
+}
+
+// issuesForMilestone returns the GitHubIssues referencing the GitHubMilestone.
+func (r *GitHubIssueReconciler) issuesForMilestone(ctx context.Context, milestone client.Object) []reconcile.Request {
+	githubIssues := &maromdanaiov1alpha1.GitHubIssueList{}
+	if err := r.List(ctx, githubIssues, client.InNamespace(milestone.GetNamespace())); err != nil {
+		r.Logger.Error(err, "Failed to list GitHubIssues")
+		return nil
+	}
+
+	var requests []reconcile.Request
+	for _, githubIssue := range githubIssues.Items {
+		if githubIssue.Spec.MilestoneRef == milestone.GetName() {
+			requests = append(requests, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(&githubIssue)})
+		}
+	}
+	return requests
+}
+
+// LookupIssue returns the GitHub issue tracked by the GitHubIssue.
+// The issue is fetched by the number recorded in the status, title matching is only used as a one-time adoption fallback.
+func (r *GitHubIssueReconciler) LookupIssue(ctx context.Context, owner string, repo string, githubIssue *maromdanaiov1alpha1.GitHubIssue, gitClient git.GitClient) (*maromdanaiov1alpha1.IssueResponse, error) {
+	if githubIssue.Status.Number != 0 {
+		issue, err := gitClient.GetIssue(ctx, owner, repo, githubIssue.Status.Number, r.Logger)
+		if err != nil {
+			return nil, err
+		}
+		if issue != nil {
+			return issue, nil
+		}
+		r.Logger.Info("Tracked issue no longer exists, falling back to title matching", "number", githubIssue.Status.Number)
+	}
+
+	issues, err := gitClient.GetRepositoryIssues(owner, repo, git.IssueListOptions{StopAt: git.MatchTitle(githubIssue.Spec.Title)}, r.Logger)
+	if err != nil {
+		r.Logger.Error(err, "Failed to list all repository issues")
+		return nil, err
+	}
+
+	return gitClient.FindIssue(issues, githubIssue.Spec.Title), nil
+}
+
 // HandleIssues creates an issue with the needed data if it doesn't exist, if it does, it updated the existing issue.
+// Existing issues are left untouched when the sync policy is ObserveOnly.
+// The body of the issues ends with the ownership marker of the GitHubIssue.
 func (r *GitHubIssueReconciler) HandleIssues(foundIssue *maromdanaiov1alpha1.IssueResponse, ctx context.Context, owner string, repo string, githubIssue *maromdanaiov1alpha1.GitHubIssue, gitClient git.GitClient) (*maromdanaiov1alpha1.IssueResponse, error) {
+	marker := r.ownershipMarker(githubIssue)
 	if foundIssue == nil {
-		newIssue, err := gitClient.CreateIssue(ctx, owner, repo, githubIssue.Spec.Title, githubIssue.Spec.Description, r.Logger)
+		request := createRequest(githubIssue)
+		request.Body = withOwnershipMarker(request.Body, marker)
+		newIssue, err := gitClient.CreateIssue(ctx, owner, repo, request, r.Logger)
 		if err != nil {
 			r.Logger.Error(err, "Failed to create issue")
 			return nil, err
 		}
+		countOperation(githubIssue, createdOperation)
+		r.recordEvent(githubIssue, corev1.EventTypeNormal, issueCreated, "Created issue #%d %s", newIssue.Number, newIssue.HTMLURL)
+		// GitHub always creates issues open, so an issue desired closed is closed right after its creation.
+		if request := markedUpdateRequest(githubIssue, newIssue, marker); request != nil {
+			return r.updateIssue(ctx, owner, repo, githubIssue, newIssue, request, gitClient)
+		}
 		return newIssue, nil
 	}
-	if foundIssue.Body != githubIssue.Spec.Description {
-		updatedIssue, err := gitClient.UpdateIssue(ctx, owner, repo, foundIssue.Number, githubIssue.Spec.Description, githubIssue.Spec.Title, r.Logger)
-		if err != nil {
-			r.Logger.Error(err, "Failed to update issue")
-			return nil, err
+	if githubIssue.Spec.SyncPolicy == maromdanaiov1alpha1.SyncPolicyObserveOnly {
+		return foundIssue, nil
+	}
+	if request := markedUpdateRequest(githubIssue, foundIssue, marker); request != nil {
+		return r.updateIssue(ctx, owner, repo, githubIssue, foundIssue, request, gitClient)
+	}
+	return foundIssue, nil
+}
+
+// updateIssue sends the update request of the found issue and emits the events of what changed.
+func (r *GitHubIssueReconciler) updateIssue(ctx context.Context, owner string, repo string, githubIssue *maromdanaiov1alpha1.GitHubIssue, foundIssue *maromdanaiov1alpha1.IssueResponse, request *maromdanaiov1alpha1.IssueRequest, gitClient git.GitClient) (*maromdanaiov1alpha1.IssueResponse, error) {
+	updatedIssue, err := gitClient.UpdateIssue(ctx, owner, repo, foundIssue.Number, request, r.Logger)
+	if err != nil {
+		r.Logger.Error(err, "Failed to update issue")
+		return nil, err
+	}
+	countOperation(githubIssue, updatedOperation)
+
+	if fields := issueDrift(githubIssue, foundIssue); len(fields) > 0 {
+		r.recordEvent(githubIssue, corev1.EventTypeNormal, issueUpdated, "Updated %s of issue #%d %s",
+			strings.Join(fields, ", "), updatedIssue.Number, updatedIssue.HTMLURL)
+	} else {
+		r.recordEvent(githubIssue, corev1.EventTypeNormal, issueUpdated, "Marked issue #%d %s as owned", updatedIssue.Number, updatedIssue.HTMLURL)
+	}
+	switch {
+	case foundIssue.State != closed && updatedIssue.State == closed:
+		countOperation(githubIssue, closedOperation)
+		r.recordEvent(githubIssue, corev1.EventTypeNormal, issueClosed, "Closed issue #%d %s", updatedIssue.Number, updatedIssue.HTMLURL)
+	case foundIssue.State == closed && updatedIssue.State != closed:
+		r.recordEvent(githubIssue, corev1.EventTypeNormal, issueReopened, "Reopened issue #%d %s", updatedIssue.Number, updatedIssue.HTMLURL)
+	}
+	return updatedIssue, nil
+}
+
+// recordEvent emits an event on the GitHubIssue when a recorder is set.
+func (r *GitHubIssueReconciler) recordEvent(githubIssue *maromdanaiov1alpha1.GitHubIssue, eventType string, reason string, messageFmt string, args ...interface{}) {
+	if r.Recorder == nil {
+		return
+	}
+	r.Recorder.Eventf(githubIssue, eventType, reason, messageFmt, args...)
+}
+
+// createRequest returns the request creating the issue described in the GitHubIssue spec.
+func createRequest(githubIssue *maromdanaiov1alpha1.GitHubIssue) *maromdanaiov1alpha1.IssueRequest {
+	request := &maromdanaiov1alpha1.IssueRequest{
+		Title: githubIssue.Spec.Title,
+		Body:  githubIssue.Spec.Description,
+		State: open,
+	}
+	if len(githubIssue.Spec.Labels) > 0 {
+		labels := githubIssue.Spec.Labels
+		request.Labels = &labels
+	}
+	if len(githubIssue.Spec.Assignees) > 0 {
+		assignees := githubIssue.Spec.Assignees
+		request.Assignees = &assignees
+	}
+	if githubIssue.Spec.Milestone != 0 {
+		milestone := maromdanaiov1alpha1.MilestoneNumber(githubIssue.Spec.Milestone)
+		request.Milestone = &milestone
+	}
+	return request
+}
+
+// updateRequest returns the request bringing the found issue to the GitHubIssue spec, or nil if the issue is up to date.
+// Labels, assignees and milestone are only sent when they differ, so removing them from the spec removes them from the issue.
+func updateRequest(githubIssue *maromdanaiov1alpha1.GitHubIssue, foundIssue *maromdanaiov1alpha1.IssueResponse) *maromdanaiov1alpha1.IssueRequest {
+	drift := issueDrift(githubIssue, foundIssue)
+	if len(drift) == 0 {
+		return nil
+	}
+
+	state := desiredState(githubIssue, foundIssue)
+	request := &maromdanaiov1alpha1.IssueRequest{
+		Title: githubIssue.Spec.Title,
+		Body:  githubIssue.Spec.Description,
+		State: state,
+	}
+	if state == closed {
+		request.StateReason = githubIssue.Spec.StateReason
+	}
+
+	for _, field := range drift {
+		switch field {
+		case labelsField:
+			labels := append([]string{}, githubIssue.Spec.Labels...)
+			request.Labels = &labels
+		case assigneesField:
+			assignees := append([]string{}, githubIssue.Spec.Assignees...)
+			request.Assignees = &assignees
+		case milestoneField:
+			milestone := maromdanaiov1alpha1.MilestoneNumber(githubIssue.Spec.Milestone)
+			request.Milestone = &milestone
+		}
+	}
+	return request
+}
+
+// issueDrift returns the fields of the found issue which differ from the GitHubIssue spec.
+func issueDrift(githubIssue *maromdanaiov1alpha1.GitHubIssue, foundIssue *maromdanaiov1alpha1.IssueResponse) []string {
+	var drift []string
+	if foundIssue.Title != githubIssue.Spec.Title {
+		drift = append(drift, titleField)
+	}
+	if stripOwnershipMarker(foundIssue.Body) != githubIssue.Spec.Description {
+		drift = append(drift, bodyField)
+	}
+
+	state := desiredState(githubIssue, foundIssue)
+	if foundIssue.State != state {
+		drift = append(drift, stateField)
+	} else if state == closed && githubIssue.Spec.StateReason != "" && foundIssue.StateReason != githubIssue.Spec.StateReason {
+		drift = append(drift, stateReasonField)
+	}
+
+	if !sameSet(githubIssue.Spec.Labels, labelNames(foundIssue)) {
+		drift = append(drift, labelsField)
+	}
+	if !sameSet(githubIssue.Spec.Assignees, assigneeLogins(foundIssue)) {
+		drift = append(drift, assigneesField)
+	}
+	if githubIssue.Spec.Milestone != milestoneNumber(foundIssue) {
+		drift = append(drift, milestoneField)
+	}
+	return drift
+}
+
+// updateDriftCondition reports in the Drifted condition whether the handled issue still differs from the GitHubIssue spec.
+// foundDrift is the drift found before the issue was handled, it is reported as corrected when the handled issue no longer has it.
+func updateDriftCondition(githubIssue *maromdanaiov1alpha1.GitHubIssue, foundDrift []string, handledIssue *maromdanaiov1alpha1.IssueResponse) {
+	var drift []string
+	if handledIssue != nil {
+		drift = issueDrift(githubIssue, handledIssue)
+	}
+	githubIssue.Status.DriftedFields = drift
+
+	condition := metav1.Condition{
+		Type:    drifted,
+		Status:  metav1.ConditionFalse,
+		Reason:  inSync,
+		Message: inSyncMessage,
+	}
+	if len(drift) > 0 {
+		condition.Status = metav1.ConditionTrue
+		condition.Reason = driftDetected
+		condition.Message = "Issue fields differ from the spec: " + strings.Join(drift, ", ")
+	} else if len(foundDrift) > 0 {
+		condition.Reason = driftCorrected
+		condition.Message = "Corrected issue fields: " + strings.Join(foundDrift, ", ")
+	}
+	setCondition(githubIssue, condition)
+}
+
+// desiredState returns the state the issue should be in, according to the spec state and state policy.
+func desiredState(githubIssue *maromdanaiov1alpha1.GitHubIssue, foundIssue *maromdanaiov1alpha1.IssueResponse) string {
+	if githubIssue.Spec.State == closed {
+		return closed
+	}
+	if githubIssue.Spec.StatePolicy == maromdanaiov1alpha1.StatePolicyRespectRemote && foundIssue.State == closed {
+		return closed
+	}
+	return open
+}
+
+// labelNames returns the names of the labels set on the issue.
+func labelNames(issue *maromdanaiov1alpha1.IssueResponse) []string {
+	names := make([]string, 0, len(issue.Labels))
+	for _, label := range issue.Labels {
+		names = append(names, label.Name)
+	}
+	return names
+}
+
+// assigneeLogins returns the logins of the users assigned to the issue.
+func assigneeLogins(issue *maromdanaiov1alpha1.IssueResponse) []string {
+	logins := make([]string, 0, len(issue.Assignees))
+	for _, assignee := range issue.Assignees {
+		logins = append(logins, assignee.Login)
+	}
+	return logins
+}
+
+// milestoneNumber returns the number of the milestone the issue belongs to, or 0 if it has none.
+func milestoneNumber(issue *maromdanaiov1alpha1.IssueResponse) int {
+	if issue.Milestone == nil {
+		return 0
+	}
+	return issue.Milestone.Number
+}
+
+// sameSet checks if both lists hold the same values, ignoring order, duplicates and case.
+func sameSet(a []string, b []string) bool {
+	setA := map[string]bool{}
+	for _, value := range a {
+		setA[strings.ToLower(value)] = true
+	}
+	setB := map[string]bool{}
+	for _, value := range b {
+		setB[strings.ToLower(value)] = true
+	}
+	if len(setA) != len(setB) {
+		return false
+	}
+	for value := range setA {
+		if !setB[value] {
+			return false
 		}
-		return updatedIssue, nil
 	}
-	return nil, nil
+	return true
+}
+
+// recordIssue records the handled issue, as it is on GitHub, in the GitHubIssue status.
+func recordIssue(githubIssue *maromdanaiov1alpha1.GitHubIssue, issue *maromdanaiov1alpha1.IssueResponse) {
+	if issue == nil {
+		return
+	}
+	githubIssue.Status.Number = issue.Number
+	githubIssue.Status.NodeID = issue.NodeID
+	githubIssue.Status.HTMLURL = issue.HTMLURL
+	githubIssue.Status.Title = issue.Title
+	githubIssue.Status.Labels = labelNames(issue)
+	githubIssue.Status.Assignees = assigneeLogins(issue)
+	githubIssue.Status.Milestone = milestoneNumber(issue)
+	githubIssue.Status.State = issue.State
+	githubIssue.Status.StateReason = issue.StateReason
 }
 
-// updateConditions updates the conditions for the GitHubIssue.
+// recordSync records in the GitHubIssue status that its spec was synced with GitHub at the given time.
+// The sync time only moves when the issue was written or the status changed, so a sync finding nothing new
+// leaves the GitHubIssue untouched and its status update doesn't trigger another reconcile.
+func recordSync(githubIssue *maromdanaiov1alpha1.GitHubIssue, previousStatus *maromdanaiov1alpha1.GitHubIssueStatus, written bool, syncedAt metav1.Time) {
+	githubIssue.Status.PullRequestCount = len(githubIssue.Status.PullRequests)
+	githubIssue.Status.ObservedGeneration = githubIssue.Generation
+
+	status := githubIssue.Status.DeepCopy()
+	status.LastSyncedTime = previousStatus.LastSyncedTime
+	if written || previousStatus.LastSyncedTime == nil || !equality.Semantic.DeepEqual(previousStatus, status) {
+		githubIssue.Status.LastSyncedTime = &syncedAt
+	}
+}
+
+// updateConditions updates the conditions for the GitHubIssue from the handled issue.
 func (r *GitHubIssueReconciler) updateConditions(githubIssue *maromdanaiov1alpha1.GitHubIssue, issue *maromdanaiov1alpha1.IssueResponse) {
+	if issue == nil {
+		return
+	}
+
 	openCondition := metav1.Condition{
-		Type:               openIssue,
-		Status:             metav1.ConditionTrue,
-		LastTransitionTime: metav1.Now(),
-		Reason:             issueExists,
-		Message:            openIssueMessage,
+		Type:    openIssue,
+		Status:  metav1.ConditionTrue,
+		Reason:  issueExists,
+		Message: openIssueMessage,
 	}
 
 	prCondition := metav1.Condition{
-		Type:               issueHasPr,
-		Status:             metav1.ConditionFalse,
-		LastTransitionTime: metav1.Now(),
-		Reason:             hasNoPr,
-		Message:            hasNoPrMessage,
+		Type:    issueHasPr,
+		Status:  metav1.ConditionFalse,
+		Reason:  hasNoPr,
+		Message: hasNoPrMessage,
 	}
 
-	if issue != nil && issue.PullRequestLinks != nil {
+	if issue.State == closed {
+		openCondition.Status = metav1.ConditionFalse
+		openCondition.Reason = issueClosed
+		openCondition.Message = closedIssueMessage
+	}
+
+	if len(githubIssue.Status.PullRequests) > 0 {
 		prCondition.Status = metav1.ConditionTrue
 		prCondition.Reason = hasPrLink
-		prCondition.Message = hasPrMessage
+		prCondition.Message = hasPrMessage + ": " + pullRequestList(githubIssue.Status.PullRequests)
+		for _, pullRequest := range githubIssue.Status.PullRequests {
+			if pullRequest.Merged {
+				prCondition.Reason = hasMergedPr
+			}
+		}
 	}
 
-	meta.SetStatusCondition(&githubIssue.Status.Conditions, openCondition)
-	meta.SetStatusCondition(&githubIssue.Status.Conditions, prCondition)
+	setCondition(githubIssue, openCondition)
+	setCondition(githubIssue, prCondition)
+}
+
+// pullRequestList returns the pull requests as owner/repo#number references, with their merge state.
+func pullRequestList(pullRequests []maromdanaiov1alpha1.LinkedPullRequest) string {
+	references := make([]string, 0, len(pullRequests))
+	for _, pullRequest := range pullRequests {
+		state := pullRequest.State
+		if pullRequest.Merged {
+			state = merged
+		}
+		references = append(references, fmt.Sprintf("%s#%d (%s)", pullRequest.Repo, pullRequest.Number, state))
+	}
+	return strings.Join(references, ", ")
 }
 
 // CheckDeletion checks if the GitHubIssue CRD has been deleted and if deleted handles it.
+// A failing deletion policy is reported in the DeletionPolicyApplied condition and retried,
+// until the deletion grace period is over and the finalizer is removed anyway.
 func (r *GitHubIssueReconciler) CheckDeletion(ctx context.Context, githubIssue *maromdanaiov1alpha1.GitHubIssue, owner string, repo string, gitClient git.GitClient) error {
 	if !githubIssue.ObjectMeta.DeletionTimestamp.IsZero() {
 		if controllerutil.ContainsFinalizer(githubIssue, finalizer) {
-			if err := gitClient.CloseIssue(ctx, owner, repo, githubIssue, r.Logger); err != nil {
-				return err
+			if err := r.applyDeletionPolicy(ctx, githubIssue, owner, repo, gitClient); err != nil {
+				if withinDeletionGracePeriod(githubIssue) {
+					r.setDeletionFailed(ctx, githubIssue, err)
+					return err
+				}
+				r.Logger.Error(err, "Deletion grace period is over, removing the finalizer without applying the deletion policy",
+					"policy", githubIssue.Spec.DeletionPolicy)
 			}
 			controllerutil.RemoveFinalizer(githubIssue, finalizer)
 
 			if err := r.Update(ctx, githubIssue); err != nil {
 				return err
 			}
-			return errors.New("GitHubIssue CR deletion has been handled")
+			return errDeletionHandled
 		}
-		return errors.New("GitHubIssue CR may have been deleted")
+		return errAlreadyDeleted
 	}
 
 	if controllerutil.AddFinalizer(githubIssue, finalizer) {
@@ -188,17 +818,130 @@ func (r *GitHubIssueReconciler) CheckDeletion(ctx context.Context, githubIssue *
 	return nil
 }
 
+// withinDeletionGracePeriod checks if the deleted GitHubIssue is still within the grace period for applying its deletion policy.
+func withinDeletionGracePeriod(githubIssue *maromdanaiov1alpha1.GitHubIssue) bool {
+	return time.Since(githubIssue.DeletionTimestamp.Time) < deletionGracePeriod
+}
+
+// applyDeletionPolicy applies the deletion policy of the GitHubIssue to its issue.
+func (r *GitHubIssueReconciler) applyDeletionPolicy(ctx context.Context, githubIssue *maromdanaiov1alpha1.GitHubIssue, owner string, repo string, gitClient git.GitClient) error {
+	policy := githubIssue.Spec.DeletionPolicy
+	if policy == maromdanaiov1alpha1.DeletionPolicyOrphan {
+		return nil
+	}
+
+	issue, err := r.LookupIssue(ctx, owner, repo, githubIssue, gitClient)
+	if err != nil {
+		return err
+	}
+	if issue == nil {
+		r.Logger.Info("Issue not found, there is nothing to apply the deletion policy to", "policy", policy)
+		return nil
+	}
+	if issue.Number != githubIssue.Status.Number && !r.mayAdopt(githubIssue, issue) {
+		r.Logger.Info("Issue with the same title was not created by the GitHubIssue, not applying the deletion policy to it", "number", issue.Number)
+		return nil
+	}
+	githubIssue.Status.Number = issue.Number
+
+	if githubIssue.Spec.DeletionComment != "" && !meta.IsStatusConditionTrue(githubIssue.Status.Conditions, deletionCommentPosted) {
+		if _, err := gitClient.CreateComment(ctx, owner, repo, issue.Number, githubIssue.Spec.DeletionComment, r.Logger); err != nil {
+			return err
+		}
+		setCondition(githubIssue, metav1.Condition{
+			Type:    deletionCommentPosted,
+			Status:  metav1.ConditionTrue,
+			Reason:  commentPosted,
+			Message: fmt.Sprintf("Deletion comment was posted on issue #%d", issue.Number),
+		})
+		if err := r.Status().Update(ctx, githubIssue); err != nil {
+			return err
+		}
+	}
+
+	switch policy {
+	case maromdanaiov1alpha1.DeletionPolicyTransfer:
+		if githubIssue.Spec.TransferTo == "" {
+			return errors.New("deletion policy Transfer requires transferTo to be set")
+		}
+		targetOwner, targetRepo := splitRepo(githubIssue.Spec.TransferTo)
+		if err := gitClient.TransferIssue(ctx, issue.NodeID, targetOwner, targetRepo, r.Logger); err != nil {
+			return err
+		}
+		r.recordEvent(githubIssue, corev1.EventTypeNormal, issueTransferred, "Transferred issue #%d %s to %s", issue.Number, issue.HTMLURL, githubIssue.Spec.TransferTo)
+		return nil
+	case maromdanaiov1alpha1.DeletionPolicyCloseNotPlanned:
+		return r.closeIssue(ctx, owner, repo, githubIssue, issue, notPlanned, gitClient)
+	case maromdanaiov1alpha1.DeletionPolicyLock:
+		if err := r.closeIssue(ctx, owner, repo, githubIssue, issue, completed, gitClient); err != nil {
+			return err
+		}
+		if err := gitClient.LockIssue(ctx, owner, repo, issue.Number, resolved, r.Logger); err != nil {
+			return err
+		}
+		r.recordEvent(githubIssue, corev1.EventTypeNormal, issueLocked, "Locked issue #%d %s", issue.Number, issue.HTMLURL)
+		return nil
+	default:
+		return r.closeIssue(ctx, owner, repo, githubIssue, issue, completed, gitClient)
+	}
+}
+
+// closeIssue closes the issue of the deleted GitHubIssue with the given state reason.
+func (r *GitHubIssueReconciler) closeIssue(ctx context.Context, owner string, repo string, githubIssue *maromdanaiov1alpha1.GitHubIssue, issue *maromdanaiov1alpha1.IssueResponse, stateReason string, gitClient git.GitClient) error {
+	if err := gitClient.CloseIssue(ctx, owner, repo, githubIssue, stateReason, r.Logger); err != nil {
+		return err
+	}
+	countOperation(githubIssue, closedOperation)
+	r.recordEvent(githubIssue, corev1.EventTypeNormal, issueClosed, "Closed issue #%d %s as %s", issue.Number, issue.HTMLURL, stateReason)
+	return nil
+}
+
+// setDeletionFailed reports the failure to apply the deletion policy in the GitHubIssue conditions.
+func (r *GitHubIssueReconciler) setDeletionFailed(ctx context.Context, githubIssue *maromdanaiov1alpha1.GitHubIssue, err error) {
+	setCondition(githubIssue, metav1.Condition{
+		Type:    deletionPolicyApplied,
+		Status:  metav1.ConditionFalse,
+		Reason:  deletionPolicyFailed,
+		Message: err.Error(),
+	})
+	setNotSynced(githubIssue, deletionPolicyFailed, err.Error())
+	r.recordEvent(githubIssue, corev1.EventTypeWarning, deletionPolicyFailed, "%s", err.Error())
+	if err := r.Status().Update(ctx, githubIssue); err != nil {
+		r.Logger.Error(err, "Failed to update GitHubIssue status")
+	}
+}
+
 // GetOwnerAndRepo returns the owner and repo parts from the githubIssue repo string.
 func GetOwnerAndRepo(githubIssue maromdanaiov1alpha1.GitHubIssue) (string, string) {
-	repoParts := strings.Split(githubIssue.Spec.Repo, "/")
+	return splitRepo(githubIssue.Spec.Repo)
+}
+
+// splitRepo returns the owner and repo parts from an owner/repo string or a repo url.
+// The owner is empty when the string has no slash.
+func splitRepo(ownerAndRepo string) (string, string) {
+	repoParts := strings.Split(strings.Trim(ownerAndRepo, "/"), "/")
+	if len(repoParts) < 2 {
+		return "", repoParts[0]
+	}
 	owner := repoParts[len(repoParts)-2]
 	repo := repoParts[len(repoParts)-1]
 	return owner, repo
 }
 
 // SetupWithManager sets up the controller with the Manager.
+// GitHubIssues are reconciled again when the GitHubMilestone they reference changes, so they get its milestone once it is created,
+// and when the ConfigMap they are rendered from changes.
+// GitHubIssues are indexed by the keys of the issue they manage, and reconciled again when a GitHubIssue managing the same issue changes.
 func (r *GitHubIssueReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	if err := mgr.GetFieldIndexer().IndexField(context.Background(), &maromdanaiov1alpha1.GitHubIssue{},
+		maromdanaiov1alpha1.IssueKeyField, maromdanaiov1alpha1.IssueKeys); err != nil {
+		return err
+	}
+
 	return ctrl.NewControllerManagedBy(mgr).
 		For(&maromdanaiov1alpha1.GitHubIssue{}).
+		Watches(&maromdanaiov1alpha1.GitHubIssue{}, handler.EnqueueRequestsFromMapFunc(r.issuesSharingKeys)).
+		Watches(&maromdanaiov1alpha1.GitHubMilestone{}, handler.EnqueueRequestsFromMapFunc(r.issuesForMilestone)).
+		Watches(&corev1.ConfigMap{}, handler.EnqueueRequestsFromMapFunc(r.issuesForConfigMap)).
 		Complete(r)
 }
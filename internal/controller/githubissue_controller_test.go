@@ -20,15 +20,26 @@ import (
 	"context"
 	"encoding/json"
 	"net/http"
+	"net/http/httptest"
+	"strings"
+	"time"
 
+	"github.com/go-logr/logr"
 	"github.com/migueleliasweb/go-github-mock/src/mock"
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	corev1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
 	maromdanaiov1alpha1 "my.domain/githubissue/api/v1alpha1"
+	"my.domain/githubissue/internal/clients/git"
+	httpClient "my.domain/githubissue/internal/clients/http"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 )
 
@@ -64,6 +75,7 @@ var _ = Describe("GitHubIssue Controller", func() {
 							Body:   issueReq.Body,
 							State:  "open",
 						}
+						w.WriteHeader(http.StatusCreated)
 						_ = json.NewEncoder(w).Encode(issueResp)
 					}),
 				),
@@ -139,6 +151,13 @@ var _ = Describe("GitHubIssue Controller", func() {
 				}
 				return false
 			}).Should(BeTrue())
+
+			By("Verifying the GitHubIssue is ready at its current generation")
+			readyCondition := meta.FindStatusCondition(githubissue.Status.Conditions, "Ready")
+			Expect(readyCondition).NotTo(BeNil())
+			Expect(readyCondition.Status).To(Equal(metav1.ConditionTrue))
+			Expect(readyCondition.ObservedGeneration).To(Equal(githubissue.Generation))
+			Expect(githubissue.Status.ObservedGeneration).To(Equal(githubissue.Generation))
 		})
 
 		// Test #2
@@ -274,5 +293,652 @@ var _ = Describe("GitHubIssue Controller", func() {
 			Expect(err.Error()).To(ContainSubstring("Failed to update issue"))
 		})
 
+		// Test #6
+		It("should record the issue number, node id and url in the status", func() {
+			issue := &maromdanaiov1alpha1.IssueResponse{
+				URL:     "https://api.github.com/repos/owner/repo/issues/7",
+				HTMLURL: "https://github.com/owner/repo/issues/7",
+				NodeID:  "I_kwDOA",
+				Number:  7,
+				Title:   "Test Issue",
+				State:   "open",
+				Labels:  []maromdanaiov1alpha1.Label{{Name: "bug"}},
+			}
+			githubIssue := &maromdanaiov1alpha1.GitHubIssue{}
+
+			recordIssue(githubIssue, issue)
+
+			Expect(githubIssue.Status.Number).To(Equal(7))
+			Expect(githubIssue.Status.NodeID).To(Equal("I_kwDOA"))
+			Expect(githubIssue.Status.HTMLURL).To(Equal("https://github.com/owner/repo/issues/7"))
+			Expect(githubIssue.Status.Title).To(Equal("Test Issue"))
+			Expect(githubIssue.Status.State).To(Equal("open"))
+			Expect(githubIssue.Status.Labels).To(Equal([]string{"bug"}))
+		})
+
+		// Test #7
+		It("should update labels, assignees and milestone only when they differ from the spec", func() {
+			githubIssue := &maromdanaiov1alpha1.GitHubIssue{
+				Spec: maromdanaiov1alpha1.GitHubIssueSpec{
+					Title:       "Test Issue",
+					Description: "This is a test issue",
+					Labels:      []string{"bug"},
+					Assignees:   []string{"MaromC"},
+				},
+			}
+			foundIssue := &maromdanaiov1alpha1.IssueResponse{
+				Number:    1,
+				Title:     "Test Issue",
+				Body:      "This is a test issue",
+				State:     "open",
+				Labels:    []maromdanaiov1alpha1.Label{{Name: "bug"}, {Name: "triage"}},
+				Assignees: []maromdanaiov1alpha1.User{{Login: "maromc"}},
+				Milestone: &maromdanaiov1alpha1.Milestone{Number: 3},
+			}
+
+			request := updateRequest(githubIssue, foundIssue)
+
+			Expect(request).NotTo(BeNil())
+			Expect(request.Labels).NotTo(BeNil())
+			Expect(*request.Labels).To(Equal([]string{"bug"}))
+			Expect(request.Assignees).To(BeNil())
+			Expect(request.Milestone).NotTo(BeNil())
+			Expect(*request.Milestone).To(BeZero())
+
+			foundIssue.Labels = []maromdanaiov1alpha1.Label{{Name: "bug"}}
+			foundIssue.Milestone = nil
+			Expect(updateRequest(githubIssue, foundIssue)).To(BeNil())
+		})
+
+		// Test #8
+		It("should only reopen an issue closed on GitHub when the state policy is Enforce", func() {
+			githubIssue := &maromdanaiov1alpha1.GitHubIssue{
+				Spec: maromdanaiov1alpha1.GitHubIssueSpec{
+					Title:       "Test Issue",
+					Description: "This is a test issue",
+					State:       "open",
+					StatePolicy: maromdanaiov1alpha1.StatePolicyEnforce,
+				},
+			}
+			foundIssue := &maromdanaiov1alpha1.IssueResponse{
+				Number:      1,
+				Title:       "Test Issue",
+				Body:        "This is a test issue",
+				State:       "closed",
+				StateReason: "completed",
+			}
+
+			request := updateRequest(githubIssue, foundIssue)
+			Expect(request).NotTo(BeNil())
+			Expect(request.State).To(Equal("open"))
+
+			githubIssue.Spec.StatePolicy = maromdanaiov1alpha1.StatePolicyRespectRemote
+			Expect(updateRequest(githubIssue, foundIssue)).To(BeNil())
+
+			githubIssue.Spec.State = "closed"
+			githubIssue.Spec.StateReason = "not_planned"
+			request = updateRequest(githubIssue, foundIssue)
+			Expect(request).NotTo(BeNil())
+			Expect(request.State).To(Equal("closed"))
+			Expect(request.StateReason).To(Equal("not_planned"))
+		})
+
+		// Test #9
+		It("should report the fields edited on GitHub in the Drifted condition", func() {
+			githubIssue := &maromdanaiov1alpha1.GitHubIssue{
+				Spec: maromdanaiov1alpha1.GitHubIssueSpec{
+					Title:       "Test Issue",
+					Description: "This is a test issue",
+					State:       "open",
+					Labels:      []string{"bug"},
+					SyncPolicy:  maromdanaiov1alpha1.SyncPolicyObserveOnly,
+				},
+			}
+			foundIssue := &maromdanaiov1alpha1.IssueResponse{
+				Number: 1,
+				Title:  "Edited Issue",
+				Body:   "This is a test issue",
+				State:  "open",
+				Labels: []maromdanaiov1alpha1.Label{{Name: "bug"}, {Name: "wontfix"}},
+			}
+
+			drift := issueDrift(githubIssue, foundIssue)
+			Expect(drift).To(Equal([]string{"title", "labels"}))
+
+			updateDriftCondition(githubIssue, drift, foundIssue)
+			condition := meta.FindStatusCondition(githubIssue.Status.Conditions, "Drifted")
+			Expect(condition).NotTo(BeNil())
+			Expect(condition.Status).To(Equal(metav1.ConditionTrue))
+			Expect(condition.Message).To(ContainSubstring("title, labels"))
+			Expect(githubIssue.Status.DriftedFields).To(Equal([]string{"title", "labels"}))
+
+			correctedIssue := &maromdanaiov1alpha1.IssueResponse{
+				Number: 1,
+				Title:  "Test Issue",
+				Body:   "This is a test issue",
+				State:  "open",
+				Labels: []maromdanaiov1alpha1.Label{{Name: "bug"}},
+			}
+			updateDriftCondition(githubIssue, drift, correctedIssue)
+			condition = meta.FindStatusCondition(githubIssue.Status.Conditions, "Drifted")
+			Expect(condition.Status).To(Equal(metav1.ConditionFalse))
+			Expect(condition.Reason).To(Equal("DriftCorrected"))
+			Expect(githubIssue.Status.DriftedFields).To(BeEmpty())
+		})
+
+		// Test #10
+		It("should set the IssueHasPR condition from the linked pull requests", func() {
+			githubIssue := &maromdanaiov1alpha1.GitHubIssue{}
+			issue := &maromdanaiov1alpha1.IssueResponse{Number: 7, State: "open"}
+			controllerReconciler := &GitHubIssueReconciler{}
+
+			controllerReconciler.updateConditions(githubIssue, issue)
+			condition := meta.FindStatusCondition(githubIssue.Status.Conditions, "IssueHasPR")
+			Expect(condition).NotTo(BeNil())
+			Expect(condition.Status).To(Equal(metav1.ConditionFalse))
+
+			githubIssue.Status.PullRequests = []maromdanaiov1alpha1.LinkedPullRequest{
+				{Repo: "owner/repo", Number: 12, State: "open"},
+				{Repo: "owner/repo", Number: 15, State: "closed", Merged: true},
+			}
+			controllerReconciler.updateConditions(githubIssue, issue)
+			condition = meta.FindStatusCondition(githubIssue.Status.Conditions, "IssueHasPR")
+			Expect(condition.Status).To(Equal(metav1.ConditionTrue))
+			Expect(condition.Reason).To(Equal("IssueHasAMergedPR"))
+			Expect(condition.Message).To(ContainSubstring("owner/repo#12 (open), owner/repo#15 (merged)"))
+		})
+
+		// Test #11
+		It("should resolve the milestone of the referenced GitHubMilestone", func() {
+			controllerReconciler := &GitHubIssueReconciler{Client: k8sClient, Scheme: k8sClient.Scheme()}
+			githubIssue := &maromdanaiov1alpha1.GitHubIssue{
+				ObjectMeta: metav1.ObjectMeta{Name: "milestone-issue", Namespace: "default"},
+				Spec: maromdanaiov1alpha1.GitHubIssueSpec{
+					Repo:         "MaromC/GitHubIssue-Operator",
+					Title:        "Milestone Issue",
+					Description:  "This is a test issue",
+					MilestoneRef: "release",
+				},
+			}
+			Expect(controllerReconciler.resolveMilestoneRef(ctx, githubIssue)).To(MatchError(errMilestoneNotReady))
+
+			milestone := &maromdanaiov1alpha1.GitHubMilestone{
+				ObjectMeta: metav1.ObjectMeta{Name: "release", Namespace: "default"},
+				Spec: maromdanaiov1alpha1.GitHubMilestoneSpec{
+					Repo:  "maromc/githubissue-operator",
+					Title: "Release",
+				},
+			}
+			Expect(k8sClient.Create(ctx, milestone)).To(Succeed())
+			defer func() {
+				Expect(k8sClient.Delete(ctx, milestone)).To(Succeed())
+			}()
+			Expect(controllerReconciler.resolveMilestoneRef(ctx, githubIssue)).To(MatchError(errMilestoneNotReady))
+
+			milestone.Status.Number = 4
+			Expect(k8sClient.Status().Update(ctx, milestone)).To(Succeed())
+			Expect(controllerReconciler.resolveMilestoneRef(ctx, githubIssue)).To(Succeed())
+			Expect(githubIssue.Spec.Milestone).To(Equal(4))
+			Expect(meta.IsStatusConditionTrue(githubIssue.Status.Conditions, "MilestoneResolved")).To(BeTrue())
+
+			githubIssue.Spec.Repo = "MaromC/other-repo"
+			Expect(controllerReconciler.resolveMilestoneRef(ctx, githubIssue)).To(MatchError(errMilestoneInOtherRepo))
+		})
+
+		// Test #12
+		It("should render the title and description from the referenced ConfigMap", func() {
+			controllerReconciler := &GitHubIssueReconciler{Client: k8sClient, Scheme: k8sClient.Scheme()}
+			githubIssue := &maromdanaiov1alpha1.GitHubIssue{
+				ObjectMeta: metav1.ObjectMeta{Name: "templated-issue", Namespace: "default"},
+				Spec: maromdanaiov1alpha1.GitHubIssueSpec{
+					Repo:     "MaromC/GitHubIssue-Operator",
+					Title:    "Templated Issue",
+					Template: &maromdanaiov1alpha1.IssueTemplate{Name: "issue-templates", Key: "incident", TitleKey: "incidentTitle"},
+					Values:   map[string]string{"service": "payments"},
+				},
+			}
+			Expect(controllerReconciler.renderTemplate(ctx, githubIssue)).To(MatchError(errTemplateNotFound))
+
+			configMap := &corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{Name: "issue-templates", Namespace: "default"},
+				Data: map[string]string{
+					"incident":      "{{ .Values.service }} is down, reported by {{ .Name }}",
+					"incidentTitle": "Incident in {{ .Values.service }}\n",
+				},
+			}
+			Expect(k8sClient.Create(ctx, configMap)).To(Succeed())
+			defer func() {
+				Expect(k8sClient.Delete(ctx, configMap)).To(Succeed())
+			}()
+
+			rendered := githubIssue.DeepCopy()
+			Expect(controllerReconciler.renderTemplate(ctx, rendered)).To(Succeed())
+			Expect(rendered.Spec.Title).To(Equal("Incident in payments"))
+			Expect(rendered.Spec.Description).To(Equal("payments is down, reported by templated-issue"))
+			Expect(meta.IsStatusConditionTrue(rendered.Status.Conditions, "TemplateRendered")).To(BeTrue())
+
+			oversized := githubIssue.DeepCopy()
+			oversized.Spec.Values["service"] = strings.Repeat("a", maromdanaiov1alpha1.MaxDescriptionLength)
+			Expect(controllerReconciler.renderTemplate(ctx, oversized)).To(MatchError(errDescriptionTooLong))
+
+			githubIssue.Spec.Values = nil
+			Expect(controllerReconciler.renderTemplate(ctx, githubIssue)).To(MatchError(errTemplateRenderFailed))
+		})
+
+		// Test #13
+		It("should only adopt issues carrying the ownership marker of the GitHubIssue", func() {
+			controllerReconciler := &GitHubIssueReconciler{ClusterID: "east"}
+			githubIssue := &maromdanaiov1alpha1.GitHubIssue{
+				ObjectMeta: metav1.ObjectMeta{Name: "owned-issue", Namespace: "default", UID: "1234"},
+				Spec: maromdanaiov1alpha1.GitHubIssueSpec{
+					Repo:        "MaromC/GitHubIssue-Operator",
+					Title:       "Owned Issue",
+					Description: "This is a test issue",
+				},
+			}
+			marker := controllerReconciler.ownershipMarker(githubIssue)
+			ownedIssue := &maromdanaiov1alpha1.IssueResponse{Number: 1, Title: "Owned Issue", Body: withOwnershipMarker("This is a test issue", marker)}
+			foreignIssue := &maromdanaiov1alpha1.IssueResponse{Number: 2, Title: "Owned Issue", Body: "Written by hand"}
+
+			Expect(stripOwnershipMarker(ownedIssue.Body)).To(Equal("This is a test issue"))
+			Expect(controllerReconciler.mayAdopt(githubIssue, ownedIssue)).To(BeTrue())
+			Expect(controllerReconciler.mayAdopt(githubIssue, foreignIssue)).To(BeFalse())
+			Expect((&GitHubIssueReconciler{ClusterID: "west"}).mayAdopt(githubIssue, ownedIssue)).To(BeFalse())
+
+			githubIssue.Spec.Adopt = true
+			Expect(controllerReconciler.mayAdopt(githubIssue, foreignIssue)).To(BeTrue())
+		})
+
+		// Test #14
+		It("should add the ownership marker to adopted issues without changing their description", func() {
+			githubIssue := &maromdanaiov1alpha1.GitHubIssue{
+				ObjectMeta: metav1.ObjectMeta{Name: "adopted-issue", Namespace: "default", UID: "5678"},
+				Spec: maromdanaiov1alpha1.GitHubIssueSpec{
+					Repo:        "MaromC/GitHubIssue-Operator",
+					Title:       "Adopted Issue",
+					Description: "This is a test issue",
+					Adopt:       true,
+				},
+			}
+			marker := (&GitHubIssueReconciler{}).ownershipMarker(githubIssue)
+			foundIssue := &maromdanaiov1alpha1.IssueResponse{Number: 3, Title: "Adopted Issue", Body: "This is a test issue", State: "open"}
+
+			Expect(issueDrift(githubIssue, foundIssue)).To(BeEmpty())
+			request := markedUpdateRequest(githubIssue, foundIssue, marker)
+			Expect(request).NotTo(BeNil())
+			Expect(request.Body).To(Equal("This is a test issue\n\n" + marker))
+			Expect(request.State).To(BeEmpty())
+
+			foundIssue.Body = request.Body
+			Expect(markedUpdateRequest(githubIssue, foundIssue, marker)).To(BeNil())
+		})
+
+		// Test #15
+		It("should emit a warning event when the GitHub rate limit is exhausted", func() {
+			recorder := record.NewFakeRecorder(10)
+			controllerReconciler := &GitHubIssueReconciler{Client: k8sClient, Scheme: k8sClient.Scheme(), Recorder: recorder}
+			githubIssue := &maromdanaiov1alpha1.GitHubIssue{
+				ObjectMeta: metav1.ObjectMeta{Name: "rate-limited-issue", Namespace: "default"},
+				Spec: maromdanaiov1alpha1.GitHubIssueSpec{
+					Repo:        "MaromC/GitHubIssue-Operator",
+					Title:       "Rate Limited Issue",
+					Description: "This is a test issue",
+				},
+			}
+			Expect(k8sClient.Create(ctx, githubIssue)).To(Succeed())
+			defer func() {
+				Expect(k8sClient.Delete(ctx, githubIssue)).To(Succeed())
+			}()
+
+			controllerReconciler.setRateLimited(ctx, githubIssue, &httpClient.RateLimitError{ResetAt: time.Now().Add(time.Hour)})
+			Expect(recorder.Events).To(Receive(HavePrefix("Warning RateLimitExceeded GitHub rate limit exhausted")))
+
+			controllerReconciler.setCredentialsInvalid(ctx, githubIssue, httpClient.ErrUnauthorized)
+			Expect(recorder.Events).To(Receive(Equal("Warning Unauthorized GitHub rejected the credentials")))
+		})
+
+		// Test #16
+		It("should count the managed issues by repo and state", func() {
+			managedIssue := func(name string, repo string, state string) *maromdanaiov1alpha1.GitHubIssue {
+				return &maromdanaiov1alpha1.GitHubIssue{
+					ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+					Spec:       maromdanaiov1alpha1.GitHubIssueSpec{Repo: repo, Title: name},
+					Status:     maromdanaiov1alpha1.GitHubIssueStatus{State: state},
+				}
+			}
+			reader := fake.NewClientBuilder().WithScheme(k8sClient.Scheme()).WithObjects(
+				managedIssue("open-issue", "MaromC/GitHubIssue-Operator", "open"),
+				managedIssue("other-open-issue", "https://github.com/maromc/githubissue-operator", "open"),
+				managedIssue("closed-issue", "MaromC/GitHubIssue-Operator", "closed"),
+				managedIssue("new-issue", "MaromC/Other", ""),
+			).Build()
+
+			expected := `
+# HELP github_managed_issues The number of issues managed by GitHubIssues, by repo and state
+# TYPE github_managed_issues gauge
+github_managed_issues{repo="maromc/githubissue-operator",state="closed"} 1
+github_managed_issues{repo="maromc/githubissue-operator",state="open"} 2
+github_managed_issues{repo="maromc/other",state="pending"} 1
+`
+			collector := &issueCollector{reader: reader, logger: logr.Discard()}
+			Expect(testutil.CollectAndCompare(collector, strings.NewReader(expected))).To(Succeed())
+		})
+
+		// Test #17
+		It("should split repos without panicking on a repo with no slash", func() {
+			owner, repo := splitRepo("https://github.com/MaromC/GitHubIssue-Operator/")
+			Expect(owner).To(Equal("MaromC"))
+			Expect(repo).To(Equal("GitHubIssue-Operator"))
+
+			owner, repo = splitRepo("GitHubIssue-Operator")
+			Expect(owner).To(BeEmpty())
+			Expect(repo).To(Equal("GitHubIssue-Operator"))
+		})
+
+		// Test #18
+		It("should mark the younger of two GitHubIssues managing the same issue as a duplicate", func() {
+			created := time.Now().Add(-time.Hour)
+			duplicateIssue := func(namespace string, name string, title string, number int, age time.Duration) *maromdanaiov1alpha1.GitHubIssue {
+				return &maromdanaiov1alpha1.GitHubIssue{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:              name,
+						Namespace:         namespace,
+						UID:               types.UID(namespace + "-" + name),
+						CreationTimestamp: metav1.NewTime(created.Add(-age)),
+					},
+					Spec:   maromdanaiov1alpha1.GitHubIssueSpec{Repo: "MaromC/GitHubIssue-Operator", Title: title},
+					Status: maromdanaiov1alpha1.GitHubIssueStatus{Number: number},
+				}
+			}
+			older := duplicateIssue("team-a", "older", "Shared Issue", 7, time.Minute)
+			younger := duplicateIssue("team-b", "younger", "Shared Issue", 0, 0)
+			renamed := duplicateIssue("team-c", "renamed", "Renamed Issue", 7, 0)
+			recorder := record.NewFakeRecorder(10)
+			controllerReconciler := &GitHubIssueReconciler{
+				Client: fake.NewClientBuilder().WithScheme(k8sClient.Scheme()).
+					WithObjects(older, younger, renamed).
+					WithStatusSubresource(&maromdanaiov1alpha1.GitHubIssue{}).
+					WithIndex(&maromdanaiov1alpha1.GitHubIssue{}, maromdanaiov1alpha1.IssueKeyField, maromdanaiov1alpha1.IssueKeys).
+					Build(),
+				Recorder: recorder,
+			}
+
+			duplicate, err := controllerReconciler.olderDuplicate(ctx, older)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(duplicate).To(BeNil())
+
+			for _, githubIssue := range []*maromdanaiov1alpha1.GitHubIssue{younger, renamed} {
+				duplicate, err = controllerReconciler.olderDuplicate(ctx, githubIssue)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(duplicate).NotTo(BeNil())
+				Expect(duplicate.Name).To(Equal("older"))
+			}
+
+			Expect(controllerReconciler.handleDuplicate(ctx, younger, duplicate)).To(Succeed())
+			condition := meta.FindStatusCondition(younger.Status.Conditions, conflict)
+			Expect(condition).NotTo(BeNil())
+			Expect(condition.Status).To(Equal(metav1.ConditionTrue))
+			Expect(condition.Reason).To(Equal(duplicateGitHubIssue))
+			Expect(recorder.Events).To(Receive(ContainSubstring("team-a/older")))
+
+			Expect(controllerReconciler.issuesSharingKeys(ctx, older)).To(ConsistOf(
+				reconcile.Request{NamespacedName: types.NamespacedName{Namespace: "team-b", Name: "younger"}},
+				reconcile.Request{NamespacedName: types.NamespacedName{Namespace: "team-c", Name: "renamed"}},
+			))
+		})
+
+		// Test #19
+		It("should record the synced generation, time and pull request count in the status", func() {
+			githubIssue := &maromdanaiov1alpha1.GitHubIssue{
+				ObjectMeta: metav1.ObjectMeta{Name: "synced", Namespace: "default", Generation: 3},
+				Status: maromdanaiov1alpha1.GitHubIssueStatus{
+					PullRequests: []maromdanaiov1alpha1.LinkedPullRequest{
+						{Repo: "MaromC/GitHubIssue-Operator", Number: 12, State: "open"},
+						{Repo: "MaromC/GitHubIssue-Operator", Number: 14, State: "closed", Merged: true},
+					},
+				},
+			}
+			syncedAt := metav1.NewTime(time.Date(2024, time.May, 1, 12, 0, 0, 0, time.UTC))
+
+			recordSync(githubIssue, githubIssue.Status.DeepCopy(), false, syncedAt)
+			Expect(githubIssue.Status.ObservedGeneration).To(Equal(int64(3)))
+			Expect(githubIssue.Status.PullRequestCount).To(Equal(2))
+			Expect(githubIssue.Status.LastSyncedTime).NotTo(BeNil())
+			Expect(githubIssue.Status.LastSyncedTime.Equal(&syncedAt)).To(BeTrue())
+
+			By("Keeping the sync time when the sync found nothing new")
+			resyncedAt := metav1.NewTime(syncedAt.Add(time.Hour))
+			recordSync(githubIssue, githubIssue.Status.DeepCopy(), false, resyncedAt)
+			Expect(githubIssue.Status.LastSyncedTime.Equal(&syncedAt)).To(BeTrue())
+
+			By("Moving the sync time when the issue was written or its status changed")
+			recordSync(githubIssue, githubIssue.Status.DeepCopy(), true, resyncedAt)
+			Expect(githubIssue.Status.LastSyncedTime.Equal(&resyncedAt)).To(BeTrue())
+
+			previousStatus := githubIssue.Status.DeepCopy()
+			githubIssue.Status.PullRequests = githubIssue.Status.PullRequests[:1]
+			changedAt := metav1.NewTime(resyncedAt.Add(time.Hour))
+			recordSync(githubIssue, previousStatus, false, changedAt)
+			Expect(githubIssue.Status.PullRequestCount).To(Equal(1))
+			Expect(githubIssue.Status.LastSyncedTime.Equal(&changedAt)).To(BeTrue())
+		})
+
+		// Test #20
+		It("should summarise the conditions in Ready and only move transition times on status changes", func() {
+			githubIssue := &maromdanaiov1alpha1.GitHubIssue{ObjectMeta: metav1.ObjectMeta{Name: "ready", Namespace: "default", Generation: 2}}
+			condition := func(conditionType string) *metav1.Condition {
+				found := meta.FindStatusCondition(githubIssue.Status.Conditions, conditionType)
+				Expect(found).NotTo(BeNil())
+				return found
+			}
+
+			setCondition(githubIssue, metav1.Condition{Type: credentialsValid, Status: metav1.ConditionTrue, Reason: credentialsResolved, Message: credentialsResolvedMessage})
+			Expect(condition(ready).Status).To(Equal(metav1.ConditionFalse))
+			Expect(condition(ready).Reason).To(Equal(notSynced))
+
+			setCondition(githubIssue, metav1.Condition{Type: synced, Status: metav1.ConditionTrue, Reason: issueSynced, Message: issueSyncedMessage})
+			Expect(condition(ready).Status).To(Equal(metav1.ConditionTrue))
+			Expect(condition(ready).Reason).To(Equal(issueReady))
+			for _, conditionType := range []string{ready, synced, credentialsValid} {
+				Expect(condition(conditionType).ObservedGeneration).To(Equal(int64(2)))
+			}
+
+			transitioned := metav1.NewTime(time.Now().Add(-time.Hour).Truncate(time.Second))
+			condition(synced).LastTransitionTime = transitioned
+			condition(ready).LastTransitionTime = transitioned
+			githubIssue.Generation = 3
+			Expect(setCondition(githubIssue, metav1.Condition{Type: synced, Status: metav1.ConditionTrue, Reason: issueSynced, Message: issueSyncedMessage})).To(BeTrue())
+			Expect(condition(synced).LastTransitionTime).To(Equal(transitioned))
+			Expect(condition(ready).LastTransitionTime).To(Equal(transitioned))
+			Expect(condition(ready).ObservedGeneration).To(Equal(int64(3)))
+
+			setCondition(githubIssue, metav1.Condition{Type: rateLimited, Status: metav1.ConditionTrue, Reason: rateLimitExceeded, Message: "rate limit exceeded"})
+			Expect(condition(ready).Status).To(Equal(metav1.ConditionFalse))
+			Expect(condition(ready).Reason).To(Equal(rateLimited))
+			Expect(condition(ready).LastTransitionTime).NotTo(Equal(transitioned))
+
+			setCondition(githubIssue, metav1.Condition{Type: credentialsValid, Status: metav1.ConditionFalse, Reason: secretNotFound, Message: "secret not found"})
+			Expect(condition(ready).Reason).To(Equal(credentialsInvalid))
+			Expect(condition(ready).Message).To(Equal("secret not found"))
+		})
+
+		// Test #21
+		It("should remove the finalizer once the deletion grace period is over even when the credentials are missing", func() {
+			deletedIssue := func(name string, deletedFor time.Duration) *maromdanaiov1alpha1.GitHubIssue {
+				return &maromdanaiov1alpha1.GitHubIssue{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:              name,
+						Namespace:         "default",
+						Finalizers:        []string{finalizer},
+						DeletionTimestamp: &metav1.Time{Time: time.Now().Add(-deletedFor)},
+					},
+					Spec: maromdanaiov1alpha1.GitHubIssueSpec{
+						Repo:           "MaromC/GitHubIssue-Operator",
+						Title:          name,
+						CredentialsRef: &maromdanaiov1alpha1.CredentialsRef{Name: "deleted-secret"},
+					},
+				}
+			}
+			recentlyDeleted := deletedIssue("recently-deleted", time.Minute)
+			longDeleted := deletedIssue("long-deleted", deletionGracePeriod+time.Minute)
+			fakeClient := fake.NewClientBuilder().WithScheme(k8sClient.Scheme()).
+				WithObjects(recentlyDeleted, longDeleted).
+				WithStatusSubresource(&maromdanaiov1alpha1.GitHubIssue{}).
+				WithIndex(&maromdanaiov1alpha1.GitHubIssue{}, maromdanaiov1alpha1.IssueKeyField, maromdanaiov1alpha1.IssueKeys).
+				Build()
+			controllerReconciler := &GitHubIssueReconciler{Client: fakeClient, Scheme: k8sClient.Scheme()}
+
+			_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(recentlyDeleted)})
+			Expect(err).To(MatchError(git.ErrSecretNotFound))
+			Expect(fakeClient.Get(ctx, client.ObjectKeyFromObject(recentlyDeleted), recentlyDeleted)).To(Succeed())
+			Expect(recentlyDeleted.Finalizers).To(ConsistOf(finalizer))
+
+			_, err = controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(longDeleted)})
+			Expect(err).NotTo(HaveOccurred())
+			err = fakeClient.Get(ctx, client.ObjectKeyFromObject(longDeleted), longDeleted)
+			Expect(apierrors.IsNotFound(err)).To(BeTrue())
+		})
+
+		// Test #22
+		It("should keep the trailing newlines of the description when stripping the ownership marker", func() {
+			githubIssue := &maromdanaiov1alpha1.GitHubIssue{
+				ObjectMeta: metav1.ObjectMeta{Name: "trailing-newline-issue", Namespace: "default", UID: "9012"},
+				Spec: maromdanaiov1alpha1.GitHubIssueSpec{
+					Repo:        "MaromC/GitHubIssue-Operator",
+					Title:       "Trailing Newline Issue",
+					Description: "Steps to reproduce\n",
+				},
+			}
+			marker := (&GitHubIssueReconciler{}).ownershipMarker(githubIssue)
+			foundIssue := &maromdanaiov1alpha1.IssueResponse{
+				Number: 4,
+				Title:  "Trailing Newline Issue",
+				Body:   withOwnershipMarker("Steps to reproduce\n", marker),
+				State:  "open",
+			}
+
+			Expect(stripOwnershipMarker(foundIssue.Body)).To(Equal("Steps to reproduce\n"))
+			Expect(stripOwnershipMarker("Steps to reproduce\n\r\n\r\n" + marker + "\r\n")).To(Equal("Steps to reproduce\n"))
+			Expect(stripOwnershipMarker(withOwnershipMarker("", marker))).To(BeEmpty())
+			Expect(issueDrift(githubIssue, foundIssue)).To(BeEmpty())
+			Expect(markedUpdateRequest(githubIssue, foundIssue, marker)).To(BeNil())
+		})
+
+		// Test #23
+		It("should not report the issue as synced when GitHub rejects the write", func() {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.Method == http.MethodGet {
+					_ = json.NewEncoder(w).Encode([]maromdanaiov1alpha1.IssueResponse{})
+					return
+				}
+				w.WriteHeader(http.StatusUnprocessableEntity)
+				_, _ = w.Write([]byte(`{"message":"Validation Failed"}`))
+			}))
+			defer server.Close()
+			git.AllowInsecureHTTP = true
+			DeferCleanup(func() { git.AllowInsecureHTTP = false })
+
+			secret := &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Name: "rejecting-github", Namespace: "default"},
+				Data:       map[string][]byte{"token": []byte("token"), "apiUrl": []byte(server.URL)},
+			}
+			githubIssue := &maromdanaiov1alpha1.GitHubIssue{
+				ObjectMeta: metav1.ObjectMeta{Name: "rejected-issue", Namespace: "default"},
+				Spec: maromdanaiov1alpha1.GitHubIssueSpec{
+					Repo:           "MaromC/GitHubIssue-Operator",
+					Title:          "Rejected Issue",
+					Description:    "This is a test issue",
+					CredentialsRef: &maromdanaiov1alpha1.CredentialsRef{Name: "rejecting-github"},
+				},
+			}
+			fakeClient := fake.NewClientBuilder().WithScheme(k8sClient.Scheme()).
+				WithObjects(secret, githubIssue).
+				WithStatusSubresource(&maromdanaiov1alpha1.GitHubIssue{}).
+				WithIndex(&maromdanaiov1alpha1.GitHubIssue{}, maromdanaiov1alpha1.IssueKeyField, maromdanaiov1alpha1.IssueKeys).
+				Build()
+			controllerReconciler := &GitHubIssueReconciler{Client: fakeClient, Scheme: k8sClient.Scheme(), Logger: logr.Discard()}
+
+			_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(githubIssue)})
+			Expect(err).To(MatchError(ContainSubstring("status code: 422")))
+
+			Expect(fakeClient.Get(ctx, client.ObjectKeyFromObject(githubIssue), githubIssue)).To(Succeed())
+			syncedCondition := meta.FindStatusCondition(githubIssue.Status.Conditions, synced)
+			Expect(syncedCondition).NotTo(BeNil())
+			Expect(syncedCondition.Status).To(Equal(metav1.ConditionFalse))
+			Expect(syncedCondition.Reason).To(Equal(syncFailed))
+			Expect(meta.IsStatusConditionFalse(githubIssue.Status.Conditions, ready)).To(BeTrue())
+			Expect(githubIssue.Status.LastSyncedTime).To(BeNil())
+			Expect(githubIssue.Status.Number).To(BeZero())
+		})
+
+		// Test #24
+		It("should post the deletion comment once while retrying a failing deletion policy", func() {
+			comments := 0
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				switch {
+				case r.Method == http.MethodGet && r.URL.Path == "/api/v3/repos/MaromC/GitHubIssue-Operator/issues/7":
+					_ = json.NewEncoder(w).Encode(maromdanaiov1alpha1.IssueResponse{Number: 7, Title: "Commented Issue", State: "open"})
+				case r.Method == http.MethodPost && r.URL.Path == "/api/v3/repos/MaromC/GitHubIssue-Operator/issues/7/comments":
+					comments++
+					w.WriteHeader(http.StatusCreated)
+					_ = json.NewEncoder(w).Encode(maromdanaiov1alpha1.CommentResponse{ID: 1})
+				default:
+					w.WriteHeader(http.StatusInternalServerError)
+				}
+			}))
+			defer server.Close()
+			git.AllowInsecureHTTP = true
+			DeferCleanup(func() { git.AllowInsecureHTTP = false })
+
+			secret := &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Name: "commenting-github", Namespace: "default"},
+				Data:       map[string][]byte{"token": []byte("token"), "apiUrl": []byte(server.URL)},
+			}
+			githubIssue := &maromdanaiov1alpha1.GitHubIssue{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:              "commented-deletion",
+					Namespace:         "default",
+					Finalizers:        []string{finalizer},
+					DeletionTimestamp: &metav1.Time{Time: time.Now()},
+				},
+				Spec: maromdanaiov1alpha1.GitHubIssueSpec{
+					Repo:            "MaromC/GitHubIssue-Operator",
+					Title:           "Commented Issue",
+					DeletionComment: "Closing as the GitHubIssue was deleted",
+					CredentialsRef:  &maromdanaiov1alpha1.CredentialsRef{Name: "commenting-github"},
+				},
+				Status: maromdanaiov1alpha1.GitHubIssueStatus{Number: 7},
+			}
+			fakeClient := fake.NewClientBuilder().WithScheme(k8sClient.Scheme()).
+				WithObjects(secret, githubIssue).
+				WithStatusSubresource(&maromdanaiov1alpha1.GitHubIssue{}).
+				WithIndex(&maromdanaiov1alpha1.GitHubIssue{}, maromdanaiov1alpha1.IssueKeyField, maromdanaiov1alpha1.IssueKeys).
+				Build()
+			controllerReconciler := &GitHubIssueReconciler{Client: fakeClient, Scheme: k8sClient.Scheme(), Logger: logr.Discard()}
+
+			for i := 0; i < 2; i++ {
+				_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(githubIssue)})
+				Expect(err).To(HaveOccurred())
+			}
+			Expect(comments).To(Equal(1))
+
+			Expect(fakeClient.Get(ctx, client.ObjectKeyFromObject(githubIssue), githubIssue)).To(Succeed())
+			Expect(githubIssue.Finalizers).To(ConsistOf(finalizer))
+			Expect(meta.IsStatusConditionTrue(githubIssue.Status.Conditions, deletionCommentPosted)).To(BeTrue())
+			Expect(meta.IsStatusConditionFalse(githubIssue.Status.Conditions, deletionPolicyApplied)).To(BeTrue())
+		})
+
+		// Test #25
+		It("should leave room in the issue body for the longest ownership marker", func() {
+			githubIssue := &maromdanaiov1alpha1.GitHubIssue{ObjectMeta: metav1.ObjectMeta{
+				Name:      strings.Repeat("n", 253),
+				Namespace: strings.Repeat("s", 63),
+				UID:       "01234567-89ab-cdef-0123-456789abcdef",
+			}}
+			controllerReconciler := &GitHubIssueReconciler{ClusterID: strings.Repeat("c", maromdanaiov1alpha1.MaxClusterIDLength)}
+			body := withOwnershipMarker(strings.Repeat("a", maromdanaiov1alpha1.MaxDescriptionLength), controllerReconciler.ownershipMarker(githubIssue))
+			Expect(body).To(HaveLen(maromdanaiov1alpha1.MaxIssueBodyLength))
+		})
+
 	})
 })
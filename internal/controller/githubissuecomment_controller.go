@@ -0,0 +1,362 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/go-logr/logr"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/retry"
+	maromdanaiov1alpha1 "my.domain/githubissue/api/v1alpha1"
+	"my.domain/githubissue/internal/clients/git"
+	httpClient "my.domain/githubissue/internal/clients/http"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+const (
+	commentFinalizer     = "githubIssueComment.finalizers.my.domain"
+	commentPosted        = "CommentPosted"
+	commentSynced        = "CommentSynced"
+	commentSyncedMessage = "Comment matches the spec"
+	commentSyncFailed    = "CommentSyncFailed"
+	issueNotReady        = "IssueNotReady"
+	invalidTarget        = "InvalidTarget"
+	// issueNotReadyRequeueDelay is how long to wait for the referenced GitHubIssue to create its issue
+	issueNotReadyRequeueDelay = 30 * time.Second
+	// commentMarkerFormat is the hidden HTML comment embedded at the end of the comments posted by the operator
+	commentMarkerFormat = "<!-- github-operator-comment: cluster=%s namespace=%s name=%s -->"
+)
+
+var (
+	errIssueNotReady = errors.New("the referenced GitHubIssue has not created its issue yet")
+	errInvalidTarget = errors.New("either issueRef or both repo and issueNumber must be set")
+)
+
+// GitHubIssueCommentReconciler reconciles a GitHubIssueComment object
+type GitHubIssueCommentReconciler struct {
+	client.Client
+	Scheme *runtime.Scheme
+	Logger logr.Logger
+	// APIReader reads the credentials secrets straight from the API server, so only get access to secrets is needed.
+	// When it is not set the client is used.
+	APIReader client.Reader
+	// ClusterID identifies the cluster in the ownership markers of the comments
+	ClusterID string
+}
+
+// commentTarget is the issue a comment is posted on, and the credentials it is posted with.
+type commentTarget struct {
+	repo           string
+	number         int
+	credentialsRef *maromdanaiov1alpha1.CredentialsRef
+}
+
+//+kubebuilder:rbac:groups=marom.dana.io.dana.io,resources=githubissuecomments,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups=marom.dana.io.dana.io,resources=githubissuecomments/status,verbs=get;update;patch
+//+kubebuilder:rbac:groups=marom.dana.io.dana.io,resources=githubissuecomments/finalizers,verbs=update
+
+// Reconcile posts the comment described by the GitHubIssueComment, keeps its body up to date and deletes it with the CR.
+func (r *GitHubIssueCommentReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	logger := r.Logger.WithValues("namespace", req.Namespace, "name", req.Name)
+	comment := &maromdanaiov1alpha1.GitHubIssueComment{}
+	if err := r.Get(ctx, req.NamespacedName, comment); err != nil {
+		if client.IgnoreNotFound(err) != nil {
+			logger.Error(err, "Failed to fetch GitHubIssueComment")
+			return ctrl.Result{}, err
+		}
+		return ctrl.Result{}, nil
+	}
+
+	result, err := r.reconcileComment(ctx, comment)
+
+	var rateLimitErr *httpClient.RateLimitError
+	if errors.As(err, &rateLimitErr) {
+		logger.Info("GitHub rate limit reached, requeueing", "resetAt", rateLimitErr.ResetAt)
+		return ctrl.Result{RequeueAfter: rateLimitRequeueDelay(rateLimitErr.ResetAt)}, nil
+	}
+	return result, err
+}
+
+// reconcileComment moves the GitHub comment closer to the state described by the GitHubIssueComment.
+func (r *GitHubIssueCommentReconciler) reconcileComment(ctx context.Context, comment *maromdanaiov1alpha1.GitHubIssueComment) (ctrl.Result, error) {
+	if !comment.DeletionTimestamp.IsZero() {
+		return ctrl.Result{}, r.deleteComment(ctx, comment)
+	}
+
+	target, err := r.resolveTarget(ctx, comment)
+	if errors.Is(err, errIssueNotReady) {
+		r.setCommentCondition(ctx, comment, metav1.ConditionFalse, issueNotReady, err.Error())
+		return ctrl.Result{RequeueAfter: issueNotReadyRequeueDelay}, nil
+	}
+	if errors.Is(err, errInvalidTarget) {
+		r.setCommentCondition(ctx, comment, metav1.ConditionFalse, invalidTarget, err.Error())
+		return ctrl.Result{}, nil
+	}
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+
+	if !controllerutil.ContainsFinalizer(comment, commentFinalizer) {
+		controllerutil.AddFinalizer(comment, commentFinalizer)
+		if err := r.Update(ctx, comment); err != nil {
+			r.Logger.Error(err, "Failed to add finalizer to GitHubIssueComment")
+			return ctrl.Result{}, err
+		}
+	}
+
+	gitClient, err := r.gitClient(ctx, comment.Namespace, target.credentialsRef)
+	if err != nil {
+		r.setCommentCondition(ctx, comment, metav1.ConditionFalse, credentialsUnreadable, err.Error())
+		return ctrl.Result{}, err
+	}
+
+	owner, repo := splitRepo(target.repo)
+	githubComment, err := r.syncComment(ctx, comment, target, owner, repo, gitClient)
+	if err != nil {
+		r.setCommentCondition(ctx, comment, metav1.ConditionFalse, commentSyncFailed, err.Error())
+		return ctrl.Result{}, err
+	}
+
+	if err := r.recordComment(ctx, comment, githubComment, target); err != nil {
+		r.Logger.Error(err, "Failed to update GitHubIssueComment status")
+		return ctrl.Result{}, err
+	}
+	return ctrl.Result{}, nil
+}
+
+// recordComment records the posted comment in the status, retrying on conflicts with the latest GitHubIssueComment
+// so the comment id is not lost and the comment posted again by the next reconcile.
+func (r *GitHubIssueCommentReconciler) recordComment(ctx context.Context, comment *maromdanaiov1alpha1.GitHubIssueComment, githubComment *maromdanaiov1alpha1.CommentResponse, target commentTarget) error {
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		comment.Status.CommentID = githubComment.ID
+		comment.Status.HTMLURL = githubComment.HTMLURL
+		comment.Status.Repo = target.repo
+		comment.Status.IssueNumber = target.number
+		meta.SetStatusCondition(&comment.Status.Conditions, metav1.Condition{
+			Type:    commentPosted,
+			Status:  metav1.ConditionTrue,
+			Reason:  commentSynced,
+			Message: commentSyncedMessage,
+		})
+		err := r.Status().Update(ctx, comment)
+		if apierrors.IsConflict(err) {
+			if getErr := r.Get(ctx, client.ObjectKeyFromObject(comment), comment); getErr != nil {
+				return getErr
+			}
+		}
+		return err
+	})
+}
+
+// ownershipMarker returns the ownership marker of the GitHubIssueComment.
+func (r *GitHubIssueCommentReconciler) ownershipMarker(comment *maromdanaiov1alpha1.GitHubIssueComment) string {
+	return fmt.Sprintf(commentMarkerFormat, r.ClusterID, comment.Namespace, comment.Name)
+}
+
+// syncComment creates the comment, or updates its body when it differs from the spec.
+// A comment deleted on GitHub, or posted on another issue than the target, is posted again,
+// unless a comment carrying the ownership marker of the GitHubIssueComment is found on the issue and adopted.
+func (r *GitHubIssueCommentReconciler) syncComment(ctx context.Context, comment *maromdanaiov1alpha1.GitHubIssueComment, target commentTarget, owner string, repo string, gitClient git.GitClient) (*maromdanaiov1alpha1.CommentResponse, error) {
+	marker := r.ownershipMarker(comment)
+	body := withOwnershipMarker(comment.Spec.Body, marker)
+	if comment.Status.CommentID != 0 && sameTarget(comment, target) {
+		githubComment, err := gitClient.GetComment(ctx, owner, repo, comment.Status.CommentID, r.Logger)
+		if err != nil {
+			return nil, err
+		}
+		if githubComment != nil {
+			if githubComment.Body == body {
+				return githubComment, nil
+			}
+			return gitClient.UpdateComment(ctx, owner, repo, comment.Status.CommentID, body, r.Logger)
+		}
+		r.Logger.Info("Tracked comment no longer exists, posting it again", "id", comment.Status.CommentID)
+	} else if comment.Status.CommentID != 0 {
+		if err := r.deletePostedComment(ctx, comment, gitClient); err != nil {
+			return nil, err
+		}
+	}
+
+	githubComments, err := gitClient.ListComments(ctx, owner, repo, target.number, r.Logger)
+	if err != nil {
+		return nil, err
+	}
+	for i := range githubComments {
+		if !strings.HasSuffix(strings.TrimSpace(githubComments[i].Body), marker) {
+			continue
+		}
+		r.Logger.Info("Adopting comment carrying the ownership marker", "id", githubComments[i].ID)
+		if githubComments[i].Body == body {
+			return &githubComments[i], nil
+		}
+		return gitClient.UpdateComment(ctx, owner, repo, githubComments[i].ID, body, r.Logger)
+	}
+
+	return gitClient.CreateComment(ctx, owner, repo, target.number, body, r.Logger)
+}
+
+// sameTarget checks if the comment recorded in the status was posted on the target issue.
+func sameTarget(comment *maromdanaiov1alpha1.GitHubIssueComment, target commentTarget) bool {
+	return strings.EqualFold(comment.Status.Repo, target.repo) && comment.Status.IssueNumber == target.number
+}
+
+// resolveTarget returns the issue the comment is posted on, from the referenced GitHubIssue or the repo and issue number.
+func (r *GitHubIssueCommentReconciler) resolveTarget(ctx context.Context, comment *maromdanaiov1alpha1.GitHubIssueComment) (commentTarget, error) {
+	if comment.Spec.IssueRef == "" {
+		if !strings.Contains(comment.Spec.Repo, "/") || comment.Spec.IssueNumber == 0 {
+			return commentTarget{}, errInvalidTarget
+		}
+		return commentTarget{
+			repo:           comment.Spec.Repo,
+			number:         comment.Spec.IssueNumber,
+			credentialsRef: comment.Spec.CredentialsRef,
+		}, nil
+	}
+
+	githubIssue := &maromdanaiov1alpha1.GitHubIssue{}
+	if err := r.Get(ctx, types.NamespacedName{Namespace: comment.Namespace, Name: comment.Spec.IssueRef}, githubIssue); err != nil {
+		if client.IgnoreNotFound(err) != nil {
+			return commentTarget{}, err
+		}
+		return commentTarget{}, fmt.Errorf("%w: GitHubIssue %s not found", errIssueNotReady, comment.Spec.IssueRef)
+	}
+	if githubIssue.Status.Number == 0 || !strings.Contains(githubIssue.Spec.Repo, "/") {
+		return commentTarget{}, errIssueNotReady
+	}
+
+	owner, repo := GetOwnerAndRepo(*githubIssue)
+	target := commentTarget{
+		repo:           owner + "/" + repo,
+		number:         githubIssue.Status.Number,
+		credentialsRef: comment.Spec.CredentialsRef,
+	}
+	if target.credentialsRef == nil {
+		target.credentialsRef = githubIssue.Spec.CredentialsRef
+	}
+	return target, nil
+}
+
+// deleteComment deletes the comment from GitHub and removes the finalizer.
+// The comment is deleted from the issue recorded in the status, as the referenced GitHubIssue may already be gone.
+// A failing deletion is retried until the deletion grace period is over and the finalizer is removed anyway.
+func (r *GitHubIssueCommentReconciler) deleteComment(ctx context.Context, comment *maromdanaiov1alpha1.GitHubIssueComment) error {
+	if !controllerutil.ContainsFinalizer(comment, commentFinalizer) {
+		return nil
+	}
+
+	if comment.Status.CommentID != 0 {
+		credentialsRef := comment.Spec.CredentialsRef
+		if credentialsRef == nil && comment.Spec.IssueRef != "" {
+			githubIssue := &maromdanaiov1alpha1.GitHubIssue{}
+			if err := r.Get(ctx, types.NamespacedName{Namespace: comment.Namespace, Name: comment.Spec.IssueRef}, githubIssue); err == nil {
+				credentialsRef = githubIssue.Spec.CredentialsRef
+			}
+		}
+		gitClient, err := r.gitClient(ctx, comment.Namespace, credentialsRef)
+		if err == nil {
+			err = r.deletePostedComment(ctx, comment, gitClient)
+		}
+		if err != nil {
+			if time.Since(comment.DeletionTimestamp.Time) < deletionGracePeriod {
+				r.setCommentCondition(ctx, comment, metav1.ConditionFalse, commentSyncFailed, err.Error())
+				return err
+			}
+			r.Logger.Error(err, "Deletion grace period is over, leaving the comment on GitHub", "id", comment.Status.CommentID)
+		}
+	}
+
+	controllerutil.RemoveFinalizer(comment, commentFinalizer)
+	if err := r.Update(ctx, comment); err != nil {
+		r.Logger.Error(err, "Failed to remove finalizer from GitHubIssueComment")
+		return err
+	}
+	return nil
+}
+
+// deletePostedComment deletes the comment recorded in the status from GitHub.
+func (r *GitHubIssueCommentReconciler) deletePostedComment(ctx context.Context, comment *maromdanaiov1alpha1.GitHubIssueComment, gitClient git.GitClient) error {
+	owner, repo := splitRepo(comment.Status.Repo)
+	if err := gitClient.DeleteComment(ctx, owner, repo, comment.Status.CommentID, r.Logger); err != nil {
+		return err
+	}
+	comment.Status.CommentID = 0
+	comment.Status.HTMLURL = ""
+	return nil
+}
+
+// gitClient initializes the git client with the given credentials.
+func (r *GitHubIssueCommentReconciler) gitClient(ctx context.Context, namespace string, credentialsRef *maromdanaiov1alpha1.CredentialsRef) (git.GitClient, error) {
+	initializer := &git.GitHubClientInitializer{HttpClient: credentialsReader(r.APIReader, r.Client)}
+	gitClient, err := initializer.InitializeGit(ctx, namespace, credentialsRef)
+	if err != nil {
+		r.Logger.Error(err, "Failed to initialize git clients")
+		return nil, err
+	}
+	return gitClient, nil
+}
+
+// setCommentCondition reports the state of the comment in the CommentPosted condition.
+func (r *GitHubIssueCommentReconciler) setCommentCondition(ctx context.Context, comment *maromdanaiov1alpha1.GitHubIssueComment, status metav1.ConditionStatus, reason string, message string) {
+	meta.SetStatusCondition(&comment.Status.Conditions, metav1.Condition{
+		Type:    commentPosted,
+		Status:  status,
+		Reason:  reason,
+		Message: message,
+	})
+	if err := r.Status().Update(ctx, comment); err != nil {
+		r.Logger.Error(err, "Failed to update GitHubIssueComment status")
+	}
+}
+
+// commentsForIssue returns the requests of the GitHubIssueComments referencing the GitHubIssue.
+func (r *GitHubIssueCommentReconciler) commentsForIssue(ctx context.Context, githubIssue client.Object) []reconcile.Request {
+	comments := &maromdanaiov1alpha1.GitHubIssueCommentList{}
+	if err := r.List(ctx, comments, client.InNamespace(githubIssue.GetNamespace())); err != nil {
+		r.Logger.Error(err, "Failed to list GitHubIssueComments")
+		return nil
+	}
+
+	var requests []reconcile.Request
+	for _, comment := range comments.Items {
+		if comment.Spec.IssueRef == githubIssue.GetName() {
+			requests = append(requests, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(&comment)})
+		}
+	}
+	return requests
+}
+
+// SetupWithManager sets up the controller with the Manager.
+// GitHubIssueComments are reconciled again when the GitHubIssue they reference changes, so they are posted once its issue is created.
+func (r *GitHubIssueCommentReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&maromdanaiov1alpha1.GitHubIssueComment{}).
+		Watches(&maromdanaiov1alpha1.GitHubIssue{}, handler.EnqueueRequestsFromMapFunc(r.commentsForIssue)).
+		Complete(r)
+}
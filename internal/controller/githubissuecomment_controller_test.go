@@ -0,0 +1,163 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+
+	"github.com/go-logr/logr"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	maromdanaiov1alpha1 "my.domain/githubissue/api/v1alpha1"
+	"my.domain/githubissue/internal/clients/git"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+var _ = Describe("GitHubIssueComment Controller", func() {
+	Context("When resolving the issue a comment is posted on", func() {
+		ctx := context.Background()
+
+		// Test #1
+		It("should use the repo and issue number when no GitHubIssue is referenced", func() {
+			controllerReconciler := &GitHubIssueCommentReconciler{Client: k8sClient, Scheme: k8sClient.Scheme()}
+			comment := &maromdanaiov1alpha1.GitHubIssueComment{
+				ObjectMeta: metav1.ObjectMeta{Name: "comment-by-number", Namespace: "default"},
+				Spec: maromdanaiov1alpha1.GitHubIssueCommentSpec{
+					Repo:        "MaromC/GitHubIssue-Operator",
+					IssueNumber: 3,
+					Body:        "This is a test comment",
+				},
+			}
+
+			target, err := controllerReconciler.resolveTarget(ctx, comment)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(target.repo).To(Equal("MaromC/GitHubIssue-Operator"))
+			Expect(target.number).To(Equal(3))
+
+			comment.Spec.IssueNumber = 0
+			_, err = controllerReconciler.resolveTarget(ctx, comment)
+			Expect(err).To(MatchError(errInvalidTarget))
+		})
+
+		// Test #2
+		It("should wait for the referenced GitHubIssue to create its issue", func() {
+			controllerReconciler := &GitHubIssueCommentReconciler{Client: k8sClient, Scheme: k8sClient.Scheme()}
+			githubIssue := &maromdanaiov1alpha1.GitHubIssue{
+				ObjectMeta: metav1.ObjectMeta{Name: "commented-issue", Namespace: "default"},
+				Spec: maromdanaiov1alpha1.GitHubIssueSpec{
+					Repo:           "MaromC/GitHubIssue-Operator",
+					Title:          "Commented Issue",
+					Description:    "This is a test issue",
+					CredentialsRef: &maromdanaiov1alpha1.CredentialsRef{Name: "issue-token"},
+				},
+			}
+			Expect(k8sClient.Create(ctx, githubIssue)).To(Succeed())
+			defer func() {
+				Expect(k8sClient.Delete(ctx, githubIssue)).To(Succeed())
+			}()
+
+			comment := &maromdanaiov1alpha1.GitHubIssueComment{
+				ObjectMeta: metav1.ObjectMeta{Name: "comment-by-ref", Namespace: "default"},
+				Spec: maromdanaiov1alpha1.GitHubIssueCommentSpec{
+					IssueRef: "commented-issue",
+					Body:     "This is a test comment",
+				},
+			}
+
+			_, err := controllerReconciler.resolveTarget(ctx, comment)
+			Expect(err).To(MatchError(errIssueNotReady))
+
+			githubIssue.Status.Number = 7
+			Expect(k8sClient.Status().Update(ctx, githubIssue)).To(Succeed())
+
+			target, err := controllerReconciler.resolveTarget(ctx, comment)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(target.repo).To(Equal("MaromC/GitHubIssue-Operator"))
+			Expect(target.number).To(Equal(7))
+			Expect(target.credentialsRef.Name).To(Equal("issue-token"))
+			Expect(sameTarget(comment, target)).To(BeFalse())
+		})
+
+		// Test #3
+		It("should adopt its marked comment instead of posting it again when its status was lost", func() {
+			var posted []maromdanaiov1alpha1.CommentResponse
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				switch {
+				case r.URL.Path == "/api/v3/repos/MaromC/GitHubIssue-Operator/issues/3/comments" && r.Method == http.MethodPost:
+					var request maromdanaiov1alpha1.CommentRequest
+					_ = json.NewDecoder(r.Body).Decode(&request)
+					posted = append(posted, maromdanaiov1alpha1.CommentResponse{ID: int64(len(posted) + 1), Body: request.Body})
+					w.WriteHeader(http.StatusCreated)
+					_ = json.NewEncoder(w).Encode(posted[len(posted)-1])
+				case r.URL.Path == "/api/v3/repos/MaromC/GitHubIssue-Operator/issues/3/comments":
+					_ = json.NewEncoder(w).Encode(append([]maromdanaiov1alpha1.CommentResponse{{ID: 100, Body: "Written by hand"}}, posted...))
+				default:
+					w.WriteHeader(http.StatusNotFound)
+				}
+			}))
+			defer server.Close()
+			git.AllowInsecureHTTP = true
+			DeferCleanup(func() { git.AllowInsecureHTTP = false })
+
+			secret := &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Name: "comment-github", Namespace: "default"},
+				Data:       map[string][]byte{"token": []byte("token"), "apiUrl": []byte(server.URL)},
+			}
+			comment := &maromdanaiov1alpha1.GitHubIssueComment{
+				ObjectMeta: metav1.ObjectMeta{Name: "adopted-comment", Namespace: "default"},
+				Spec: maromdanaiov1alpha1.GitHubIssueCommentSpec{
+					Repo:           "MaromC/GitHubIssue-Operator",
+					IssueNumber:    3,
+					Body:           "This is a test comment",
+					CredentialsRef: &maromdanaiov1alpha1.CredentialsRef{Name: "comment-github"},
+				},
+			}
+			fakeClient := fake.NewClientBuilder().WithScheme(k8sClient.Scheme()).
+				WithObjects(secret, comment).
+				WithStatusSubresource(&maromdanaiov1alpha1.GitHubIssueComment{}).
+				Build()
+			controllerReconciler := &GitHubIssueCommentReconciler{Client: fakeClient, Scheme: k8sClient.Scheme(), Logger: logr.Discard(), ClusterID: "east"}
+			request := reconcile.Request{NamespacedName: client.ObjectKeyFromObject(comment)}
+
+			_, err := controllerReconciler.Reconcile(ctx, request)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(posted).To(HaveLen(1))
+			Expect(strings.HasPrefix(posted[0].Body, "This is a test comment\n\n")).To(BeTrue())
+			Expect(posted[0].Body).To(HaveSuffix(controllerReconciler.ownershipMarker(comment)))
+
+			By("Losing the recorded comment id")
+			Expect(fakeClient.Get(ctx, request.NamespacedName, comment)).To(Succeed())
+			Expect(comment.Status.CommentID).To(Equal(int64(1)))
+			comment.Status.CommentID = 0
+			Expect(fakeClient.Status().Update(ctx, comment)).To(Succeed())
+
+			_, err = controllerReconciler.Reconcile(ctx, request)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(posted).To(HaveLen(1))
+			Expect(fakeClient.Get(ctx, request.NamespacedName, comment)).To(Succeed())
+			Expect(comment.Status.CommentID).To(Equal(int64(1)))
+		})
+	})
+})
@@ -0,0 +1,224 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/go-logr/logr"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	maromdanaiov1alpha1 "my.domain/githubissue/api/v1alpha1"
+	"my.domain/githubissue/internal/clients/git"
+	httpClient "my.domain/githubissue/internal/clients/http"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	labelsSynced     = "LabelsSynced"
+	labelsSyncFailed = "LabelsSyncFailed"
+)
+
+// GitHubLabelSetReconciler reconciles a GitHubLabelSet object
+type GitHubLabelSetReconciler struct {
+	client.Client
+	Scheme *runtime.Scheme
+	Logger logr.Logger
+	// APIReader reads the credentials secrets straight from the API server, so only get access to secrets is needed.
+	// When it is not set the client is used.
+	APIReader client.Reader
+}
+
+// labelUpdate is an update of the existing label with the given name.
+type labelUpdate struct {
+	name    string
+	request maromdanaiov1alpha1.LabelRequest
+}
+
+// labelPlan holds the changes bringing the labels of a repo to a GitHubLabelSet.
+type labelPlan struct {
+	create []maromdanaiov1alpha1.LabelRequest
+	update []labelUpdate
+	prune  []string
+}
+
+//+kubebuilder:rbac:groups=marom.dana.io.dana.io,resources=githublabelsets,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups=marom.dana.io.dana.io,resources=githublabelsets/status,verbs=get;update;patch
+
+// Reconcile creates, recolors and renames the labels of the repo to match the GitHubLabelSet, and prunes the others when asked to.
+// Labels are left in the repo when the GitHubLabelSet is deleted, as deleting them would remove them from every issue.
+func (r *GitHubLabelSetReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	logger := r.Logger.WithValues("namespace", req.Namespace, "name", req.Name)
+	labelSet := &maromdanaiov1alpha1.GitHubLabelSet{}
+	if err := r.Get(ctx, req.NamespacedName, labelSet); err != nil {
+		if client.IgnoreNotFound(err) != nil {
+			logger.Error(err, "Failed to fetch GitHubLabelSet")
+			return ctrl.Result{}, err
+		}
+		return ctrl.Result{}, nil
+	}
+	if !labelSet.DeletionTimestamp.IsZero() {
+		return ctrl.Result{}, nil
+	}
+
+	err := r.reconcileLabels(ctx, labelSet)
+
+	var rateLimitErr *httpClient.RateLimitError
+	if errors.As(err, &rateLimitErr) {
+		logger.Info("GitHub rate limit reached, requeueing", "resetAt", rateLimitErr.ResetAt)
+		return ctrl.Result{RequeueAfter: rateLimitRequeueDelay(rateLimitErr.ResetAt)}, nil
+	}
+	if err != nil {
+		r.setLabelsCondition(ctx, labelSet, metav1.ConditionFalse, labelsSyncFailed, err.Error())
+		return ctrl.Result{}, err
+	}
+	return ctrl.Result{}, nil
+}
+
+// reconcileLabels applies the label plan of the GitHubLabelSet to its repo and records it in the status.
+func (r *GitHubLabelSetReconciler) reconcileLabels(ctx context.Context, labelSet *maromdanaiov1alpha1.GitHubLabelSet) error {
+	initializer := &git.GitHubClientInitializer{HttpClient: credentialsReader(r.APIReader, r.Client)}
+	gitClient, err := initializer.InitializeGit(ctx, labelSet.Namespace, labelSet.Spec.CredentialsRef)
+	if err != nil {
+		r.Logger.Error(err, "Failed to initialize git clients")
+		return err
+	}
+
+	owner, repo := splitRepo(labelSet.Spec.Repo)
+	existing, err := gitClient.ListLabels(ctx, owner, repo, r.Logger)
+	if err != nil {
+		return err
+	}
+
+	plan := planLabels(labelSet.Spec.Labels, existing, labelSet.Spec.Prune)
+	for _, update := range plan.update {
+		request := update.request
+		if err := gitClient.UpdateLabel(ctx, owner, repo, update.name, &request, r.Logger); err != nil {
+			return err
+		}
+	}
+	for _, request := range plan.create {
+		request := request
+		if err := gitClient.CreateLabel(ctx, owner, repo, &request, r.Logger); err != nil {
+			return err
+		}
+	}
+	for _, name := range plan.prune {
+		if err := gitClient.DeleteLabel(ctx, owner, repo, name, r.Logger); err != nil {
+			return err
+		}
+	}
+
+	labelSet.Status.Labels = make([]string, 0, len(labelSet.Spec.Labels))
+	for _, label := range labelSet.Spec.Labels {
+		labelSet.Status.Labels = append(labelSet.Status.Labels, label.Name)
+	}
+	labelSet.Status.Pruned = plan.prune
+	meta.SetStatusCondition(&labelSet.Status.Conditions, metav1.Condition{
+		Type:    labelsSynced,
+		Status:  metav1.ConditionTrue,
+		Reason:  labelsSynced,
+		Message: fmt.Sprintf("Created %d, updated %d and pruned %d labels", len(plan.create), len(plan.update), len(plan.prune)),
+	})
+	if err := r.Status().Update(ctx, labelSet); err != nil {
+		r.Logger.Error(err, "Failed to update GitHubLabelSet status")
+		return err
+	}
+	return nil
+}
+
+// planLabels returns the changes bringing the existing labels to the desired ones.
+// Label names are matched ignoring case, like GitHub does, and an existing label with a previous name is renamed.
+func planLabels(desired []maromdanaiov1alpha1.LabelSpec, existing []maromdanaiov1alpha1.Label, prune bool) labelPlan {
+	existingByName := map[string]maromdanaiov1alpha1.Label{}
+	for _, label := range existing {
+		existingByName[strings.ToLower(label.Name)] = label
+	}
+	kept := map[string]bool{}
+	for _, label := range desired {
+		kept[strings.ToLower(label.Name)] = true
+	}
+
+	var plan labelPlan
+	for _, label := range desired {
+		request := maromdanaiov1alpha1.LabelRequest{
+			Color:       strings.ToLower(label.Color),
+			Description: label.Description,
+		}
+
+		if current, ok := existingByName[strings.ToLower(label.Name)]; ok {
+			if current.Name != label.Name {
+				request.NewName = label.Name
+			}
+			if request.NewName != "" || !strings.EqualFold(current.Color, label.Color) || current.Description != label.Description {
+				plan.update = append(plan.update, labelUpdate{name: current.Name, request: request})
+			}
+			continue
+		}
+
+		renamed := false
+		for _, previousName := range label.PreviousNames {
+			current, ok := existingByName[strings.ToLower(previousName)]
+			if !ok || kept[strings.ToLower(previousName)] {
+				continue
+			}
+			request.NewName = label.Name
+			plan.update = append(plan.update, labelUpdate{name: current.Name, request: request})
+			kept[strings.ToLower(previousName)] = true
+			renamed = true
+			break
+		}
+		if !renamed {
+			request.Name = label.Name
+			plan.create = append(plan.create, request)
+		}
+	}
+
+	if prune {
+		for _, label := range existing {
+			if !kept[strings.ToLower(label.Name)] {
+				plan.prune = append(plan.prune, label.Name)
+			}
+		}
+	}
+	return plan
+}
+
+// setLabelsCondition reports the state of the labels in the LabelsSynced condition.
+func (r *GitHubLabelSetReconciler) setLabelsCondition(ctx context.Context, labelSet *maromdanaiov1alpha1.GitHubLabelSet, status metav1.ConditionStatus, reason string, message string) {
+	meta.SetStatusCondition(&labelSet.Status.Conditions, metav1.Condition{
+		Type:    labelsSynced,
+		Status:  status,
+		Reason:  reason,
+		Message: message,
+	})
+	if err := r.Status().Update(ctx, labelSet); err != nil {
+		r.Logger.Error(err, "Failed to update GitHubLabelSet status")
+	}
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *GitHubLabelSetReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&maromdanaiov1alpha1.GitHubLabelSet{}).
+		Complete(r)
+}
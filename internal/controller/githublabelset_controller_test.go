@@ -0,0 +1,59 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	maromdanaiov1alpha1 "my.domain/githubissue/api/v1alpha1"
+)
+
+var _ = Describe("GitHubLabelSet Controller", func() {
+	Context("When planning the label changes", func() {
+		desired := []maromdanaiov1alpha1.LabelSpec{
+			{Name: "bug", Color: "D73A4A", Description: "Something is broken"},
+			{Name: "enhancement", Color: "a2eeef", PreviousNames: []string{"feature"}},
+			{Name: "Docs", Color: "0075ca"},
+			{Name: "triage", Color: "000000"},
+		}
+		existing := []maromdanaiov1alpha1.Label{
+			{Name: "bug", Color: "d73a4a", Description: "Something is broken"},
+			{Name: "feature", Color: "a2eeef"},
+			{Name: "docs", Color: "0075ca"},
+			{Name: "wontfix", Color: "ffffff"},
+		}
+
+		// Test #1
+		It("should create missing labels and rename labels with a previous name", func() {
+			plan := planLabels(desired, existing, false)
+
+			Expect(plan.create).To(Equal([]maromdanaiov1alpha1.LabelRequest{{Name: "triage", Color: "000000"}}))
+			Expect(plan.update).To(Equal([]labelUpdate{
+				{name: "feature", request: maromdanaiov1alpha1.LabelRequest{NewName: "enhancement", Color: "a2eeef"}},
+				{name: "docs", request: maromdanaiov1alpha1.LabelRequest{NewName: "Docs", Color: "0075ca"}},
+			}))
+			Expect(plan.prune).To(BeEmpty())
+		})
+
+		// Test #2
+		It("should only prune the labels which are not in the set when asked to", func() {
+			plan := planLabels(desired, existing, true)
+
+			Expect(plan.prune).To(Equal([]string{"wontfix"}))
+		})
+	})
+})
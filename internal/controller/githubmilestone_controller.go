@@ -0,0 +1,206 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/go-logr/logr"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	maromdanaiov1alpha1 "my.domain/githubissue/api/v1alpha1"
+	"my.domain/githubissue/internal/clients/git"
+	httpClient "my.domain/githubissue/internal/clients/http"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	milestoneSynced     = "MilestoneSynced"
+	milestoneSyncFailed = "MilestoneSyncFailed"
+	// dueDateLayout formats the day of a due date, the only part of it GitHub keeps
+	dueDateLayout = "2006-01-02"
+)
+
+// GitHubMilestoneReconciler reconciles a GitHubMilestone object
+type GitHubMilestoneReconciler struct {
+	client.Client
+	Scheme *runtime.Scheme
+	Logger logr.Logger
+	// APIReader reads the credentials secrets straight from the API server, so only get access to secrets is needed.
+	// When it is not set the client is used.
+	APIReader client.Reader
+}
+
+//+kubebuilder:rbac:groups=marom.dana.io.dana.io,resources=githubmilestones,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups=marom.dana.io.dana.io,resources=githubmilestones/status,verbs=get;update;patch
+
+// Reconcile creates or adopts the milestone of the GitHubMilestone, keeps it matching the spec and records its progress.
+// Milestones are left in the repo when the GitHubMilestone is deleted, as deleting them would remove them from every issue.
+func (r *GitHubMilestoneReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	logger := r.Logger.WithValues("namespace", req.Namespace, "name", req.Name)
+	milestone := &maromdanaiov1alpha1.GitHubMilestone{}
+	if err := r.Get(ctx, req.NamespacedName, milestone); err != nil {
+		if client.IgnoreNotFound(err) != nil {
+			logger.Error(err, "Failed to fetch GitHubMilestone")
+			return ctrl.Result{}, err
+		}
+		return ctrl.Result{}, nil
+	}
+	if !milestone.DeletionTimestamp.IsZero() {
+		return ctrl.Result{}, nil
+	}
+
+	err := r.reconcileMilestone(ctx, milestone)
+
+	var rateLimitErr *httpClient.RateLimitError
+	if errors.As(err, &rateLimitErr) {
+		logger.Info("GitHub rate limit reached, requeueing", "resetAt", rateLimitErr.ResetAt)
+		return ctrl.Result{RequeueAfter: rateLimitRequeueDelay(rateLimitErr.ResetAt)}, nil
+	}
+	if err != nil {
+		r.setMilestoneCondition(ctx, milestone, metav1.ConditionFalse, milestoneSyncFailed, err.Error())
+		return ctrl.Result{}, err
+	}
+	return ctrl.Result{}, nil
+}
+
+// reconcileMilestone brings the milestone of the GitHubMilestone to its spec and records it in the status.
+func (r *GitHubMilestoneReconciler) reconcileMilestone(ctx context.Context, milestone *maromdanaiov1alpha1.GitHubMilestone) error {
+	initializer := &git.GitHubClientInitializer{HttpClient: credentialsReader(r.APIReader, r.Client)}
+	gitClient, err := initializer.InitializeGit(ctx, milestone.Namespace, milestone.Spec.CredentialsRef)
+	if err != nil {
+		r.Logger.Error(err, "Failed to initialize git clients")
+		return err
+	}
+
+	owner, repo := splitRepo(milestone.Spec.Repo)
+	found, err := r.lookupMilestone(ctx, owner, repo, milestone, gitClient)
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case found == nil:
+		found, err = gitClient.CreateMilestone(ctx, owner, repo, milestoneRequest(milestone), r.Logger)
+	case !milestoneMatches(milestone, found):
+		found, err = gitClient.UpdateMilestone(ctx, owner, repo, found.Number, milestoneRequest(milestone), r.Logger)
+	}
+	if err != nil {
+		return err
+	}
+
+	milestone.Status.Number = found.Number
+	milestone.Status.HTMLURL = found.HTMLURL
+	milestone.Status.State = found.State
+	milestone.Status.OpenIssues = found.OpenIssues
+	milestone.Status.ClosedIssues = found.ClosedIssues
+	meta.SetStatusCondition(&milestone.Status.Conditions, metav1.Condition{
+		Type:    milestoneSynced,
+		Status:  metav1.ConditionTrue,
+		Reason:  milestoneSynced,
+		Message: fmt.Sprintf("Milestone #%d is %s with %d open and %d closed issues", found.Number, found.State, found.OpenIssues, found.ClosedIssues),
+	})
+	if err := r.Status().Update(ctx, milestone); err != nil {
+		r.Logger.Error(err, "Failed to update GitHubMilestone status")
+		return err
+	}
+	return nil
+}
+
+// lookupMilestone returns the milestone tracked by the GitHubMilestone.
+// The milestone is fetched by the number recorded in the status, an existing milestone with the same title is adopted otherwise.
+func (r *GitHubMilestoneReconciler) lookupMilestone(ctx context.Context, owner string, repo string, milestone *maromdanaiov1alpha1.GitHubMilestone, gitClient git.GitClient) (*maromdanaiov1alpha1.Milestone, error) {
+	if milestone.Status.Number != 0 {
+		found, err := gitClient.GetMilestone(ctx, owner, repo, milestone.Status.Number, r.Logger)
+		if err != nil {
+			return nil, err
+		}
+		if found != nil {
+			return found, nil
+		}
+		r.Logger.Info("Tracked milestone no longer exists, falling back to title matching", "number", milestone.Status.Number)
+	}
+
+	milestones, err := gitClient.ListMilestones(ctx, owner, repo, r.Logger)
+	if err != nil {
+		return nil, err
+	}
+	for i := range milestones {
+		if milestones[i].Title == milestone.Spec.Title {
+			return &milestones[i], nil
+		}
+	}
+	return nil, nil
+}
+
+// milestoneRequest returns the request bringing a milestone to the GitHubMilestone spec.
+func milestoneRequest(milestone *maromdanaiov1alpha1.GitHubMilestone) *maromdanaiov1alpha1.MilestoneRequest {
+	return &maromdanaiov1alpha1.MilestoneRequest{
+		Title:       milestone.Spec.Title,
+		State:       desiredMilestoneState(milestone),
+		Description: milestone.Spec.Description,
+		DueOn:       milestone.Spec.DueOn,
+	}
+}
+
+// desiredMilestoneState returns the state the milestone should be in, milestones are open unless stated otherwise.
+func desiredMilestoneState(milestone *maromdanaiov1alpha1.GitHubMilestone) string {
+	if milestone.Spec.State == "" {
+		return open
+	}
+	return milestone.Spec.State
+}
+
+// milestoneMatches reports whether the found milestone matches the GitHubMilestone spec.
+func milestoneMatches(milestone *maromdanaiov1alpha1.GitHubMilestone, found *maromdanaiov1alpha1.Milestone) bool {
+	return found.Title == milestone.Spec.Title &&
+		found.Description == milestone.Spec.Description &&
+		found.State == desiredMilestoneState(milestone) &&
+		sameDueDate(found.DueOn, milestone.Spec.DueOn)
+}
+
+// sameDueDate reports whether both due dates fall on the same day, GitHub moves the time of the due dates it is given.
+func sameDueDate(a *metav1.Time, b *metav1.Time) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	return a.UTC().Format(dueDateLayout) == b.UTC().Format(dueDateLayout)
+}
+
+// setMilestoneCondition reports the state of the milestone in the MilestoneSynced condition.
+func (r *GitHubMilestoneReconciler) setMilestoneCondition(ctx context.Context, milestone *maromdanaiov1alpha1.GitHubMilestone, status metav1.ConditionStatus, reason string, message string) {
+	meta.SetStatusCondition(&milestone.Status.Conditions, metav1.Condition{
+		Type:    milestoneSynced,
+		Status:  status,
+		Reason:  reason,
+		Message: message,
+	})
+	if err := r.Status().Update(ctx, milestone); err != nil {
+		r.Logger.Error(err, "Failed to update GitHubMilestone status")
+	}
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *GitHubMilestoneReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&maromdanaiov1alpha1.GitHubMilestone{}).
+		Complete(r)
+}
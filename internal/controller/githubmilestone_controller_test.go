@@ -0,0 +1,66 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	maromdanaiov1alpha1 "my.domain/githubissue/api/v1alpha1"
+)
+
+var _ = Describe("GitHubMilestone Controller", func() {
+	Context("When comparing the milestone with the spec", func() {
+		dueOn := metav1.NewTime(time.Date(2024, time.June, 30, 0, 0, 0, 0, time.UTC))
+		milestone := &maromdanaiov1alpha1.GitHubMilestone{
+			Spec: maromdanaiov1alpha1.GitHubMilestoneSpec{
+				Repo:        "MaromC/GitHubIssue-Operator",
+				Title:       "v1.0",
+				Description: "First stable release",
+				DueOn:       &dueOn,
+			},
+		}
+
+		// Test #1
+		It("should match a milestone due on the same day", func() {
+			githubDueOn := metav1.NewTime(time.Date(2024, time.June, 30, 7, 0, 0, 0, time.UTC))
+			found := &maromdanaiov1alpha1.Milestone{
+				Number:      3,
+				Title:       "v1.0",
+				Description: "First stable release",
+				State:       "open",
+				DueOn:       &githubDueOn,
+			}
+
+			Expect(milestoneMatches(milestone, found)).To(BeTrue())
+
+			found.DueOn = nil
+			Expect(milestoneMatches(milestone, found)).To(BeFalse())
+		})
+
+		// Test #2
+		It("should request the desired state and due date", func() {
+			request := milestoneRequest(milestone)
+
+			Expect(request.Title).To(Equal("v1.0"))
+			Expect(request.State).To(Equal("open"))
+			Expect(request.DueOn).To(Equal(&dueOn))
+		})
+	})
+})
@@ -0,0 +1,118 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/prometheus/client_golang/prometheus"
+	maromdanaiov1alpha1 "my.domain/githubissue/api/v1alpha1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+const (
+	createdOperation = "created"
+	updatedOperation = "updated"
+	closedOperation  = "closed"
+	// pendingState is the state label of the GitHubIssues whose issue was not created yet
+	pendingState = "pending"
+	// collectTimeout bounds the listing of the GitHubIssues when the metrics are scraped
+	collectTimeout = 10 * time.Second
+)
+
+var (
+	issueOperationsCounter = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "github_issue_operations_total",
+		Help: "The number of issues created, updated and closed on GitHub, by repo and operation",
+	}, []string{"repo", "operation"})
+
+	managedIssuesDesc = prometheus.NewDesc(
+		"github_managed_issues",
+		"The number of issues managed by GitHubIssues, by repo and state",
+		[]string{"repo", "state"}, nil,
+	)
+)
+
+func init() {
+	metrics.Registry.MustRegister(issueOperationsCounter)
+}
+
+// countOperation counts an operation done on an issue of the repo of the GitHubIssue.
+func countOperation(githubIssue *maromdanaiov1alpha1.GitHubIssue, operation string) {
+	issueOperationsCounter.WithLabelValues(repoLabel(githubIssue.Spec.Repo), operation).Inc()
+}
+
+// repoLabel returns the lowercase owner/repo of a repo, which may be given as a URL.
+func repoLabel(repo string) string {
+	if !strings.Contains(repo, "/") {
+		return strings.ToLower(repo)
+	}
+	owner, name := splitRepo(repo)
+	return strings.ToLower(owner + "/" + name)
+}
+
+// managedIssueKey identifies a series of the managed issues gauge.
+type managedIssueKey struct {
+	repo  string
+	state string
+}
+
+// issueCollector counts the managed issues from the GitHubIssues every time the metrics are scraped,
+// so the counts are right whichever replica or reconcile changed them.
+type issueCollector struct {
+	reader client.Reader
+	logger logr.Logger
+}
+
+// RegisterIssueCollector registers the collector of the managed issues gauge on the metrics registry.
+// The reader should be the cached client of the manager, so scrapes don't reach the API server.
+func RegisterIssueCollector(reader client.Reader, logger logr.Logger) error {
+	return metrics.Registry.Register(&issueCollector{reader: reader, logger: logger})
+}
+
+// Describe implements prometheus.Collector.
+func (c *issueCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- managedIssuesDesc
+}
+
+// Collect implements prometheus.Collector.
+func (c *issueCollector) Collect(ch chan<- prometheus.Metric) {
+	ctx, cancel := context.WithTimeout(context.Background(), collectTimeout)
+	defer cancel()
+
+	githubIssues := &maromdanaiov1alpha1.GitHubIssueList{}
+	if err := c.reader.List(ctx, githubIssues); err != nil {
+		c.logger.Error(err, "Failed to list GitHubIssues for the managed issues metric")
+		return
+	}
+
+	counts := map[managedIssueKey]int{}
+	for _, githubIssue := range githubIssues.Items {
+		state := githubIssue.Status.State
+		if state == "" {
+			state = pendingState
+		}
+		counts[managedIssueKey{repo: repoLabel(githubIssue.Spec.Repo), state: state}]++
+	}
+	for key, count := range counts {
+		ch <- prometheus.MustNewConstMetric(managedIssuesDesc, prometheus.GaugeValue, float64(count), key.repo, key.state)
+	}
+}
@@ -0,0 +1,115 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	maromdanaiov1alpha1 "my.domain/githubissue/api/v1alpha1"
+)
+
+const (
+	conflict          = "Conflict"
+	issueNotOwned     = "IssueNotOwned"
+	issueOwned        = "IssueOwned"
+	issueOwnedMessage = "Issue is owned by the GitHubIssue"
+)
+
+var (
+	// ownershipMarkerPattern matches the ownership marker at the end of an issue body
+	ownershipMarkerPattern = regexp.MustCompile(`<!-- github-operator: cluster=(\S*) namespace=(\S+) name=(\S+) uid=(\S+) -->\s*$`)
+	// ownershipMarkerSeparators are the blank line withOwnershipMarker separates the marker from the description with,
+	// GitHub may return it with carriage returns
+	ownershipMarkerSeparators = []string{maromdanaiov1alpha1.OwnershipMarkerSeparator, "\r\n\r\n"}
+)
+
+// ownershipMarker returns the ownership marker of the GitHubIssue.
+func (r *GitHubIssueReconciler) ownershipMarker(githubIssue *maromdanaiov1alpha1.GitHubIssue) string {
+	return fmt.Sprintf(maromdanaiov1alpha1.OwnershipMarkerFormat, r.ClusterID, githubIssue.Namespace, githubIssue.Name, githubIssue.UID)
+}
+
+// ownedBy reports whether the body holds the ownership marker of the GitHubIssue.
+// The uid is not compared, so a GitHubIssue recreated with the same name owns the issues of the previous one.
+func (r *GitHubIssueReconciler) ownedBy(body string, githubIssue *maromdanaiov1alpha1.GitHubIssue) bool {
+	match := ownershipMarkerPattern.FindStringSubmatch(body)
+	return match != nil && match[1] == r.ClusterID && match[2] == githubIssue.Namespace && match[3] == githubIssue.Name
+}
+
+// mayAdopt reports whether the issue found by its title may be adopted by the GitHubIssue.
+// Issues created by the GitHubIssue are always adopted, other issues only when adopt is set.
+func (r *GitHubIssueReconciler) mayAdopt(githubIssue *maromdanaiov1alpha1.GitHubIssue, foundIssue *maromdanaiov1alpha1.IssueResponse) bool {
+	return githubIssue.Spec.Adopt || r.ownedBy(foundIssue.Body, githubIssue)
+}
+
+// withOwnershipMarker returns the description with the ownership marker at its end.
+func withOwnershipMarker(description string, marker string) string {
+	if description == "" {
+		return marker
+	}
+	return description + maromdanaiov1alpha1.OwnershipMarkerSeparator + marker
+}
+
+// stripOwnershipMarker returns the body without its ownership marker, which is the description the issue was given.
+// Only the separator withOwnershipMarker adds is stripped, so trailing newlines of the description are kept.
+func stripOwnershipMarker(body string) string {
+	location := ownershipMarkerPattern.FindStringIndex(body)
+	if location == nil {
+		return body
+	}
+	description := body[:location[0]]
+	for _, separator := range ownershipMarkerSeparators {
+		if strings.HasSuffix(description, separator) {
+			return strings.TrimSuffix(description, separator)
+		}
+	}
+	return description
+}
+
+// markedUpdateRequest returns the update request of the issue with the ownership marker in the body.
+// Issues missing the marker, such as adopted ones, are updated to add it.
+func markedUpdateRequest(githubIssue *maromdanaiov1alpha1.GitHubIssue, foundIssue *maromdanaiov1alpha1.IssueResponse, marker string) *maromdanaiov1alpha1.IssueRequest {
+	request := updateRequest(githubIssue, foundIssue)
+	if request == nil {
+		if strings.HasSuffix(foundIssue.Body, marker) {
+			return nil
+		}
+		request = &maromdanaiov1alpha1.IssueRequest{Title: githubIssue.Spec.Title}
+	}
+	request.Body = withOwnershipMarker(githubIssue.Spec.Description, marker)
+	return request
+}
+
+// setConflict reports the issue with the same title which may not be adopted in the Conflict condition.
+func (r *GitHubIssueReconciler) setConflict(ctx context.Context, githubIssue *maromdanaiov1alpha1.GitHubIssue, foundIssue *maromdanaiov1alpha1.IssueResponse) {
+	message := fmt.Sprintf("Issue #%d %s has the same title but was not created by this GitHubIssue, set adopt to adopt it", foundIssue.Number, foundIssue.HTMLURL)
+	setCondition(githubIssue, metav1.Condition{
+		Type:    conflict,
+		Status:  metav1.ConditionTrue,
+		Reason:  issueNotOwned,
+		Message: message,
+	})
+	setNotSynced(githubIssue, issueNotOwned, message)
+	r.recordEvent(githubIssue, corev1.EventTypeWarning, issueNotOwned, "%s", message)
+	if err := r.Status().Update(ctx, githubIssue); err != nil {
+		r.Logger.Error(err, "Failed to update GitHubIssue status")
+	}
+}
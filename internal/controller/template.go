@@ -0,0 +1,177 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"text/template"
+	"unicode/utf8"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	maromdanaiov1alpha1 "my.domain/githubissue/api/v1alpha1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+const (
+	templateRendered     = "TemplateRendered"
+	templateNotFound     = "TemplateNotFound"
+	templateRenderFailed = "TemplateRenderFailed"
+	descriptionTooLong   = "DescriptionTooLong"
+	defaultTemplateKey   = "body"
+)
+
+var (
+	errTemplateNotFound     = errors.New("the issue template was not found")
+	errTemplateRenderFailed = errors.New("the issue template could not be rendered")
+	errDescriptionTooLong   = errors.New("the rendered description is too long")
+)
+
+// templateData is what the issue templates are rendered with.
+type templateData struct {
+	Name        string
+	Namespace   string
+	Labels      map[string]string
+	Annotations map[string]string
+	Spec        maromdanaiov1alpha1.GitHubIssueSpec
+	Values      map[string]string
+}
+
+// renderTemplate renders the templates of the ConfigMap referenced by the GitHubIssue as the title and description of the spec.
+// The rendered text is only kept for this reconcile, the spec is never written back.
+func (r *GitHubIssueReconciler) renderTemplate(ctx context.Context, githubIssue *maromdanaiov1alpha1.GitHubIssue) error {
+	issueTemplate := githubIssue.Spec.Template
+	if issueTemplate == nil {
+		meta.RemoveStatusCondition(&githubIssue.Status.Conditions, templateRendered)
+		return nil
+	}
+
+	configMap := &corev1.ConfigMap{}
+	if err := r.Get(ctx, types.NamespacedName{Namespace: githubIssue.Namespace, Name: issueTemplate.Name}, configMap); err != nil {
+		if client.IgnoreNotFound(err) != nil {
+			return err
+		}
+		return fmt.Errorf("%w: ConfigMap %s not found", errTemplateNotFound, issueTemplate.Name)
+	}
+
+	data := templateData{
+		Name:        githubIssue.Name,
+		Namespace:   githubIssue.Namespace,
+		Labels:      githubIssue.Labels,
+		Annotations: githubIssue.Annotations,
+		Spec:        githubIssue.Spec,
+		Values:      githubIssue.Spec.Values,
+	}
+	key := issueTemplate.Key
+	if key == "" {
+		key = defaultTemplateKey
+	}
+	description, err := renderIssueTemplate(configMap, key, data)
+	if err != nil {
+		return err
+	}
+	// The webhook only sees the spec description, the rendered one is checked against the same limit here.
+	if length := utf8.RuneCountInString(description); length > maromdanaiov1alpha1.MaxDescriptionLength {
+		return fmt.Errorf("%w: key %s rendered %d characters, more than the %d allowed",
+			errDescriptionTooLong, key, length, maromdanaiov1alpha1.MaxDescriptionLength)
+	}
+	title := githubIssue.Spec.Title
+	if issueTemplate.TitleKey != "" {
+		rendered, err := renderIssueTemplate(configMap, issueTemplate.TitleKey, data)
+		if err != nil {
+			return err
+		}
+		// Title templates usually end with a newline in ConfigMaps, which GitHub would keep in the title.
+		title = strings.TrimSpace(rendered)
+		if title == "" {
+			return fmt.Errorf("%w: key %s rendered an empty title", errTemplateRenderFailed, issueTemplate.TitleKey)
+		}
+	}
+
+	githubIssue.Spec.Title = title
+	githubIssue.Spec.Description = description
+	setCondition(githubIssue, metav1.Condition{
+		Type:    templateRendered,
+		Status:  metav1.ConditionTrue,
+		Reason:  templateRendered,
+		Message: fmt.Sprintf("Issue was rendered from ConfigMap %s", configMap.Name),
+	})
+	return nil
+}
+
+// renderIssueTemplate renders the template under the given key of the ConfigMap.
+// Referencing a value which is not set is an error rather than an empty string.
+func renderIssueTemplate(configMap *corev1.ConfigMap, key string, data templateData) (string, error) {
+	text, ok := configMap.Data[key]
+	if !ok {
+		return "", fmt.Errorf("%w: key %s not found in ConfigMap %s", errTemplateNotFound, key, configMap.Name)
+	}
+
+	parsed, err := template.New(key).Option("missingkey=error").Parse(text)
+	if err != nil {
+		return "", fmt.Errorf("%w: %s", errTemplateRenderFailed, err.Error())
+	}
+	var rendered strings.Builder
+	if err := parsed.Execute(&rendered, data); err != nil {
+		return "", fmt.Errorf("%w: %s", errTemplateRenderFailed, err.Error())
+	}
+	return rendered.String(), nil
+}
+
+// setTemplateNotRendered reports the failure to render the issue template in the GitHubIssue conditions.
+func (r *GitHubIssueReconciler) setTemplateNotRendered(ctx context.Context, githubIssue *maromdanaiov1alpha1.GitHubIssue, err error) {
+	reason := templateRenderFailed
+	switch {
+	case errors.Is(err, errTemplateNotFound):
+		reason = templateNotFound
+	case errors.Is(err, errDescriptionTooLong):
+		reason = descriptionTooLong
+	}
+	setCondition(githubIssue, metav1.Condition{
+		Type:    templateRendered,
+		Status:  metav1.ConditionFalse,
+		Reason:  reason,
+		Message: err.Error(),
+	})
+	setNotSynced(githubIssue, reason, err.Error())
+	if err := r.Status().Update(ctx, githubIssue); err != nil {
+		r.Logger.Error(err, "Failed to update GitHubIssue status")
+	}
+}
+
+// issuesForConfigMap returns the GitHubIssues rendered from the ConfigMap, so they are rendered again when it changes.
+func (r *GitHubIssueReconciler) issuesForConfigMap(ctx context.Context, configMap client.Object) []reconcile.Request {
+	githubIssues := &maromdanaiov1alpha1.GitHubIssueList{}
+	if err := r.List(ctx, githubIssues, client.InNamespace(configMap.GetNamespace())); err != nil {
+		r.Logger.Error(err, "Failed to list GitHubIssues")
+		return nil
+	}
+
+	var requests []reconcile.Request
+	for _, githubIssue := range githubIssues.Items {
+		if githubIssue.Spec.Template != nil && githubIssue.Spec.Template.Name == configMap.GetName() {
+			requests = append(requests, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(&githubIssue)})
+		}
+	}
+	return requests
+}
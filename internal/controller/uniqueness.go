@@ -0,0 +1,123 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	maromdanaiov1alpha1 "my.domain/githubissue/api/v1alpha1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+const (
+	// duplicateGitHubIssue is the Conflict reason of the GitHubIssues managing the same issue as an older GitHubIssue
+	duplicateGitHubIssue = "DuplicateGitHubIssue"
+)
+
+// olderDuplicate returns the oldest GitHubIssue of the cluster managing the same issue as the GitHubIssue and created before it,
+// or nil when the GitHubIssue is the oldest. GitHubIssues manage the same issue when they share a key of the IssueKeyField index.
+func (r *GitHubIssueReconciler) olderDuplicate(ctx context.Context, githubIssue *maromdanaiov1alpha1.GitHubIssue) (*maromdanaiov1alpha1.GitHubIssue, error) {
+	var oldest *maromdanaiov1alpha1.GitHubIssue
+	for _, key := range maromdanaiov1alpha1.IssueKeys(githubIssue) {
+		githubIssues := &maromdanaiov1alpha1.GitHubIssueList{}
+		if err := r.List(ctx, githubIssues, client.MatchingFields{maromdanaiov1alpha1.IssueKeyField: key}); err != nil {
+			return nil, err
+		}
+		for i := range githubIssues.Items {
+			other := &githubIssues.Items[i]
+			if other.UID == githubIssue.UID || !createdBefore(other, githubIssue) {
+				continue
+			}
+			if oldest == nil || createdBefore(other, oldest) {
+				oldest = other
+			}
+		}
+	}
+	return oldest, nil
+}
+
+// createdBefore reports whether the first GitHubIssue was created before the second one.
+// GitHubIssues created in the same second are ordered by namespace and name, so exactly one of them is the oldest.
+func createdBefore(first *maromdanaiov1alpha1.GitHubIssue, second *maromdanaiov1alpha1.GitHubIssue) bool {
+	if !first.CreationTimestamp.Equal(&second.CreationTimestamp) {
+		return first.CreationTimestamp.Before(&second.CreationTimestamp)
+	}
+	if first.Namespace != second.Namespace {
+		return first.Namespace < second.Namespace
+	}
+	return first.Name < second.Name
+}
+
+// handleDuplicate stops the GitHubIssue from writing to the issue of the older GitHubIssue.
+// It reports the older GitHubIssue in the Conflict condition, and removes the finalizer of a deleted duplicate
+// without applying its deletion policy, as the issue is not its own.
+func (r *GitHubIssueReconciler) handleDuplicate(ctx context.Context, githubIssue *maromdanaiov1alpha1.GitHubIssue, older *maromdanaiov1alpha1.GitHubIssue) error {
+	if !githubIssue.DeletionTimestamp.IsZero() {
+		if controllerutil.RemoveFinalizer(githubIssue, finalizer) {
+			return r.Update(ctx, githubIssue)
+		}
+		return nil
+	}
+
+	message := fmt.Sprintf("The issue is already managed by the older GitHubIssue %s/%s, this GitHubIssue does not write to it", older.Namespace, older.Name)
+	if condition := meta.FindStatusCondition(githubIssue.Status.Conditions, conflict); condition != nil &&
+		condition.Status == metav1.ConditionTrue && condition.Reason == duplicateGitHubIssue && condition.Message == message {
+		return nil
+	}
+	setCondition(githubIssue, metav1.Condition{
+		Type:    conflict,
+		Status:  metav1.ConditionTrue,
+		Reason:  duplicateGitHubIssue,
+		Message: message,
+	})
+	setNotSynced(githubIssue, duplicateGitHubIssue, message)
+	r.recordEvent(githubIssue, corev1.EventTypeWarning, duplicateGitHubIssue, "%s", message)
+	if err := r.Status().Update(ctx, githubIssue); err != nil {
+		r.Logger.Error(err, "Failed to update GitHubIssue status")
+	}
+	return nil
+}
+
+// issuesSharingKeys maps a GitHubIssue to the other GitHubIssues managing the same issue,
+// so a duplicate takes over the issue once the older GitHubIssue is deleted or moved to another issue.
+func (r *GitHubIssueReconciler) issuesSharingKeys(ctx context.Context, obj client.Object) []reconcile.Request {
+	seen := map[types.NamespacedName]bool{}
+	var requests []reconcile.Request
+	for _, key := range maromdanaiov1alpha1.IssueKeys(obj) {
+		githubIssues := &maromdanaiov1alpha1.GitHubIssueList{}
+		if err := r.List(ctx, githubIssues, client.MatchingFields{maromdanaiov1alpha1.IssueKeyField: key}); err != nil {
+			r.Logger.Error(err, "Failed to list GitHubIssues managing the same issue", "key", key)
+			continue
+		}
+		for _, githubIssue := range githubIssues.Items {
+			name := client.ObjectKeyFromObject(&githubIssue)
+			if githubIssue.UID == obj.GetUID() || seen[name] {
+				continue
+			}
+			seen[name] = true
+			requests = append(requests, reconcile.Request{NamespacedName: name})
+		}
+	}
+	return requests
+}
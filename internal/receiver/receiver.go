@@ -0,0 +1,263 @@
+package receiver
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-logr/logr"
+	maromdanaiov1alpha1 "my.domain/githubissue/api/v1alpha1"
+	"my.domain/githubissue/internal/controller"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+var (
+	// Path is the path GitHub webhook deliveries are received on
+	Path = "/github/webhook"
+	// LastEventAnnotation is set to the id of the last delivery concerning a GitHubIssue,
+	// the change is seen by the watch of the controller whichever replica received the delivery
+	LastEventAnnotation = "marom.dana.io/last-event"
+
+	eventHeader     = "X-GitHub-Event"
+	deliveryHeader  = "X-GitHub-Delivery"
+	signatureHeader = "X-Hub-Signature-256"
+	signaturePrefix = "sha256="
+	// maxPayloadSize is the size GitHub caps webhook payloads at
+	maxPayloadSize  int64 = 25 << 20
+	shutdownTimeout       = 10 * time.Second
+
+	pingEvent         = "ping"
+	issuesEvent       = "issues"
+	issueCommentEvent = "issue_comment"
+	pullRequestEvent  = "pull_request"
+
+	// issueReference matches the #number and owner/repo#number references pull requests link issues with
+	issueReference = regexp.MustCompile(`(?:([\w.-]+/[\w.-]+))?#(\d+)\b`)
+)
+
+// Receiver receives GitHub webhook deliveries and annotates the GitHubIssues they concern,
+// so changes on GitHub are noticed without waiting for the next resync.
+type Receiver struct {
+	// Client lists the GitHubIssues deliveries are matched against and annotates them
+	Client client.Client
+	// Secret is the webhook secret deliveries are signed with
+	Secret []byte
+	// Address is the address the receiver binds to
+	Address string
+	Logger  logr.Logger
+}
+
+// delivery defines the parts of the issues, issue_comment and pull_request payloads used to find the concerned GitHubIssues
+type delivery struct {
+	Action      string               `json:"action"`
+	Issue       *deliveryIssue       `json:"issue"`
+	PullRequest *deliveryPullRequest `json:"pull_request"`
+	Repository  deliveryRepository   `json:"repository"`
+}
+
+type deliveryIssue struct {
+	Number      int             `json:"number"`
+	Title       string          `json:"title"`
+	Body        string          `json:"body"`
+	PullRequest json.RawMessage `json:"pull_request"`
+}
+
+type deliveryPullRequest struct {
+	Number int    `json:"number"`
+	Title  string `json:"title"`
+	Body   string `json:"body"`
+}
+
+type deliveryRepository struct {
+	FullName string `json:"full_name"`
+}
+
+// issueKey identifies an issue on GitHub.
+type issueKey struct {
+	repo   string
+	number int
+}
+
+// NeedLeaderElection serves the webhook endpoint on every replica, as the Service routes deliveries to all of them.
+// The annotations set by any replica are seen by the controller of the leader.
+func (r *Receiver) NeedLeaderElection() bool {
+	return false
+}
+
+// Start serves the webhook endpoint until the context is done.
+func (r *Receiver) Start(ctx context.Context) error {
+	mux := http.NewServeMux()
+	mux.Handle(Path, r)
+	server := &http.Server{
+		Addr:              r.Address,
+		Handler:           mux,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	errs := make(chan error, 1)
+	go func() {
+		r.Logger.Info("Serving GitHub webhooks", "address", r.Address, "path", Path)
+		if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			errs <- err
+		}
+		close(errs)
+	}()
+
+	select {
+	case err := <-errs:
+		return err
+	case <-ctx.Done():
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+		defer cancel()
+		return server.Shutdown(shutdownCtx)
+	}
+}
+
+// ServeHTTP verifies the delivery signature and annotates the GitHubIssues the delivery concerns.
+func (r *Receiver) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	body, err := io.ReadAll(http.MaxBytesReader(w, req.Body, maxPayloadSize))
+	if err != nil {
+		http.Error(w, "unable to read payload", http.StatusBadRequest)
+		return
+	}
+	if !VerifySignature(r.Secret, body, req.Header.Get(signatureHeader)) {
+		r.Logger.Info("Rejected GitHub webhook delivery with an invalid signature")
+		http.Error(w, "invalid signature", http.StatusUnauthorized)
+		return
+	}
+
+	eventType := req.Header.Get(eventHeader)
+	switch eventType {
+	case pingEvent:
+		w.WriteHeader(http.StatusOK)
+		return
+	case issuesEvent, issueCommentEvent, pullRequestEvent:
+	default:
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	var payload delivery
+	if err := json.Unmarshal(body, &payload); err != nil {
+		http.Error(w, "invalid payload", http.StatusBadRequest)
+		return
+	}
+
+	githubIssues, err := r.concernedIssues(req.Context(), &payload)
+	if err != nil {
+		r.Logger.Error(err, "Failed to list GitHubIssues for webhook delivery")
+		http.Error(w, "unable to list GitHubIssues", http.StatusInternalServerError)
+		return
+	}
+
+	deliveryID := req.Header.Get(deliveryHeader)
+	if deliveryID == "" {
+		deliveryID = time.Now().UTC().Format(time.RFC3339Nano)
+	}
+	for i := range githubIssues {
+		if err := r.annotate(req.Context(), &githubIssues[i], deliveryID); err != nil {
+			r.Logger.Error(err, "Failed to annotate GitHubIssue for webhook delivery",
+				"namespace", githubIssues[i].Namespace, "name", githubIssues[i].Name)
+			http.Error(w, "unable to annotate GitHubIssues", http.StatusInternalServerError)
+			return
+		}
+	}
+	r.Logger.Info("Received GitHub webhook delivery", "event", eventType, "action", payload.Action,
+		"repo", payload.Repository.FullName, "annotated", len(githubIssues))
+	w.WriteHeader(http.StatusAccepted)
+}
+
+// annotate sets the last event annotation of the GitHubIssue to the delivery id through the API server.
+// GitHubIssues deleted since they were listed are skipped.
+func (r *Receiver) annotate(ctx context.Context, githubIssue *maromdanaiov1alpha1.GitHubIssue, deliveryID string) error {
+	patch := client.MergeFrom(githubIssue.DeepCopy())
+	if githubIssue.Annotations == nil {
+		githubIssue.Annotations = map[string]string{}
+	}
+	githubIssue.Annotations[LastEventAnnotation] = deliveryID
+	return client.IgnoreNotFound(r.Client.Patch(ctx, githubIssue, patch))
+}
+
+// VerifySignature checks the X-Hub-Signature-256 header is the HMAC of the payload with the webhook secret.
+func VerifySignature(secret, payload []byte, signature string) bool {
+	if len(secret) == 0 || !strings.HasPrefix(signature, signaturePrefix) {
+		return false
+	}
+	expected, err := hex.DecodeString(strings.TrimPrefix(signature, signaturePrefix))
+	if err != nil {
+		return false
+	}
+	mac := hmac.New(sha256.New, secret)
+	mac.Write(payload)
+	return hmac.Equal(mac.Sum(nil), expected)
+}
+
+// concernedIssues returns the GitHubIssues of the issues the delivery is about.
+// Pull request deliveries, and comments on pull requests, concern the issues referenced in the pull request title and body.
+func (r *Receiver) concernedIssues(ctx context.Context, payload *delivery) ([]maromdanaiov1alpha1.GitHubIssue, error) {
+	repo := strings.ToLower(payload.Repository.FullName)
+	keys := map[issueKey]bool{}
+	titles := map[string]bool{}
+
+	if payload.Issue != nil && len(payload.Issue.PullRequest) == 0 {
+		keys[issueKey{repo: repo, number: payload.Issue.Number}] = true
+		titles[payload.Issue.Title] = true
+	}
+	if payload.PullRequest != nil {
+		referencedIssues(repo, payload.PullRequest.Title+"\n"+payload.PullRequest.Body, keys)
+	}
+	if payload.Issue != nil && len(payload.Issue.PullRequest) > 0 {
+		referencedIssues(repo, payload.Issue.Title+"\n"+payload.Issue.Body, keys)
+	}
+	if len(keys) == 0 {
+		return nil, nil
+	}
+
+	githubIssueList := &maromdanaiov1alpha1.GitHubIssueList{}
+	if err := r.Client.List(ctx, githubIssueList); err != nil {
+		return nil, err
+	}
+
+	var githubIssues []maromdanaiov1alpha1.GitHubIssue
+	for _, githubIssue := range githubIssueList.Items {
+		if !strings.Contains(githubIssue.Spec.Repo, "/") {
+			continue
+		}
+		owner, name := controller.GetOwnerAndRepo(githubIssue)
+		issueRepo := strings.ToLower(owner + "/" + name)
+		if keys[issueKey{repo: issueRepo, number: githubIssue.Status.Number}] ||
+			(githubIssue.Status.Number == 0 && issueRepo == repo && titles[githubIssue.Spec.Title]) {
+			githubIssues = append(githubIssues, githubIssue)
+		}
+	}
+	return githubIssues, nil
+}
+
+// referencedIssues adds the issues referenced in the text to the keys.
+func referencedIssues(repo string, text string, keys map[issueKey]bool) {
+	for _, match := range issueReference.FindAllStringSubmatch(text, -1) {
+		number, err := strconv.Atoi(match[2])
+		if err != nil {
+			continue
+		}
+		referencedRepo := repo
+		if match[1] != "" {
+			referencedRepo = strings.ToLower(match[1])
+		}
+		keys[issueKey{repo: referencedRepo, number: number}] = true
+	}
+}
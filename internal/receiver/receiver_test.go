@@ -0,0 +1,136 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package receiver
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+
+	"github.com/go-logr/logr"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	maromdanaiov1alpha1 "my.domain/githubissue/api/v1alpha1"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+var _ = Describe("Receiver", func() {
+	var (
+		secret     = []byte("webhook-secret")
+		deliveries int
+		receiver   *Receiver
+	)
+
+	githubIssue := func(name string, repo string, title string, number int) *maromdanaiov1alpha1.GitHubIssue {
+		return &maromdanaiov1alpha1.GitHubIssue{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+			Spec:       maromdanaiov1alpha1.GitHubIssueSpec{Repo: repo, Title: title},
+			Status:     maromdanaiov1alpha1.GitHubIssueStatus{Number: number},
+		}
+	}
+
+	sign := func(payload string) string {
+		mac := hmac.New(sha256.New, secret)
+		mac.Write([]byte(payload))
+		return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+	}
+
+	deliver := func(eventType string, payload string, signature string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, Path, strings.NewReader(payload))
+		req.Header.Set("X-GitHub-Event", eventType)
+		req.Header.Set("X-Hub-Signature-256", signature)
+		deliveries++
+		req.Header.Set("X-GitHub-Delivery", fmt.Sprintf("delivery-%d", deliveries))
+		recorder := httptest.NewRecorder()
+		receiver.ServeHTTP(recorder, req)
+		return recorder
+	}
+
+	// annotated returns the names of the GitHubIssues annotated with the last delivery
+	annotated := func() []string {
+		githubIssues := &maromdanaiov1alpha1.GitHubIssueList{}
+		Expect(receiver.Client.List(context.Background(), githubIssues)).To(Succeed())
+		var names []string
+		for _, githubIssue := range githubIssues.Items {
+			if githubIssue.Annotations[LastEventAnnotation] == fmt.Sprintf("delivery-%d", deliveries) {
+				names = append(names, githubIssue.Name)
+			}
+		}
+		return names
+	}
+
+	BeforeEach(func() {
+		scheme := runtime.NewScheme()
+		Expect(maromdanaiov1alpha1.AddToScheme(scheme)).To(Succeed())
+		receiver = &Receiver{
+			Client: fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+				githubIssue("numbered", "MaromC/GitHubIssue-Operator", "Numbered Issue", 7),
+				githubIssue("unnumbered", "https://github.com/MaromC/GitHubIssue-Operator", "New Issue", 0),
+				githubIssue("other-repo", "MaromC/Other", "Numbered Issue", 7),
+			).Build(),
+			Secret: secret,
+			Logger: logr.Discard(),
+		}
+	})
+
+	It("should verify the payload signature", func() {
+		Expect(VerifySignature(secret, []byte("payload"), sign("payload"))).To(BeTrue())
+		Expect(VerifySignature(secret, []byte("tampered"), sign("payload"))).To(BeFalse())
+		Expect(VerifySignature(secret, []byte("payload"), strings.TrimPrefix(sign("payload"), "sha256="))).To(BeFalse())
+		Expect(VerifySignature(nil, []byte("payload"), sign("payload"))).To(BeFalse())
+	})
+
+	It("should reject deliveries with an invalid signature", func() {
+		payload := `{"action":"closed","issue":{"number":7},"repository":{"full_name":"MaromC/GitHubIssue-Operator"}}`
+		Expect(deliver("issues", payload, sign("other")).Code).To(Equal(http.StatusUnauthorized))
+		Expect(annotated()).To(BeEmpty())
+	})
+
+	It("should annotate the GitHubIssues of the delivered issue", func() {
+		payload := `{"action":"closed","issue":{"number":7,"title":"Numbered Issue"},"repository":{"full_name":"maromc/githubissue-operator"}}`
+		Expect(deliver("issues", payload, sign(payload)).Code).To(Equal(http.StatusAccepted))
+		Expect(annotated()).To(ConsistOf("numbered"))
+
+		payload = `{"action":"opened","issue":{"number":8,"title":"New Issue"},"repository":{"full_name":"MaromC/GitHubIssue-Operator"}}`
+		Expect(deliver("issue_comment", payload, sign(payload)).Code).To(Equal(http.StatusAccepted))
+		Expect(annotated()).To(ConsistOf("unnumbered"))
+	})
+
+	It("should annotate the GitHubIssues referenced by a pull request", func() {
+		payload := `{"action":"opened","pull_request":{"number":9,"title":"Fix it","body":"Closes #7, see MaromC/Other#7"},"repository":{"full_name":"MaromC/GitHubIssue-Operator"}}`
+		Expect(deliver("pull_request", payload, sign(payload)).Code).To(Equal(http.StatusAccepted))
+		Expect(annotated()).To(ConsistOf("numbered", "other-repo"))
+	})
+
+	It("should serve on every replica", func() {
+		Expect(receiver.NeedLeaderElection()).To(BeFalse())
+	})
+
+	It("should ignore other events", func() {
+		payload := `{"zen":"Keep it logically awesome."}`
+		Expect(deliver("ping", payload, sign(payload)).Code).To(Equal(http.StatusOK))
+		Expect(deliver("push", payload, sign(payload)).Code).To(Equal(http.StatusNoContent))
+		Expect(annotated()).To(BeEmpty())
+	})
+})